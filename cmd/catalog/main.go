@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/config"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+	"github.com/kuduzow/team-4-pharmacy/internal/storage"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	db := config.SetUpDatabaseConnection()
+
+	medicineRepo := repository.NewMedicineRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
+	importJobRepo := repository.NewImportJobRepository(db)
+	medicineImageRepo := repository.NewMedicineImageRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	manufacturerRepo := repository.NewManufacturerRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "uploads"
+	}
+	mediaStorage, err := storage.NewLocalStorage(mediaDir, os.Getenv("MEDIA_BASE_URL"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ошибка:", err)
+		os.Exit(1)
+	}
+
+	medicineImageService := services.NewMedicineImageService(medicineImageRepo, medicineRepo, mediaStorage)
+	medicineService := services.NewMedicineService(medicineRepo, categoryRepo, batchRepo, medicineImageService, productRepo, manufacturerRepo)
+	catalogService := services.NewCatalogService(medicineService, medicineRepo, categoryRepo, importJobRepo)
+	reviewService := services.NewReviewService(reviewRepo, userRepo, &services.ReviewFilter{})
+
+	switch os.Args[1] {
+	case "import":
+		err = runImport(catalogService, os.Args[2:])
+	case "export":
+		err = runExport(catalogService, os.Args[2:])
+	case "recompute-ratings":
+		err = runRecomputeRatings(reviewService)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ошибка:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "использование:")
+	fmt.Fprintln(os.Stderr, "  catalog import <файл.csv|файл.xlsx>")
+	fmt.Fprintln(os.Stderr, "  catalog export [-format csv|xlsx] [-out файл]")
+	fmt.Fprintln(os.Stderr, "  catalog recompute-ratings")
+}
+
+func runImport(catalog services.CatalogService, args []string) error {
+	if len(args) != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	job, records, err := catalog.PrepareImport(file.Name(), file)
+	if err != nil {
+		return err
+	}
+
+	if err := catalog.RunImport(job, records); err != nil {
+		return err
+	}
+
+	fmt.Printf("импорт #%d: строк %d, создано %d, обновлено %d, ошибок %d\n",
+		job.ID, job.TotalRows, job.Created, job.Updated, job.Failed)
+
+	finished, err := catalog.GetImportJob(job.ID)
+	if err != nil {
+		return err
+	}
+	for _, rowError := range finished.Errors {
+		fmt.Printf("  строка %d (%s): %s\n", rowError.Row, rowError.SKU, rowError.Message)
+	}
+
+	return nil
+}
+
+func runExport(catalog services.CatalogService, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", services.CatalogFormatCSV, "формат выгрузки: csv или xlsx")
+	out := flags.String("out", "", "файл для выгрузки, по умолчанию stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return catalog.Export(os.Stdout, *format, repository.MedicineFilter{})
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+
+	if err := catalog.Export(file, *format, repository.MedicineFilter{}); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func runRecomputeRatings(reviews services.ModelService) error {
+	updated, err := reviews.RecomputeRatings()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("рейтинги пересчитаны: лекарств %d\n", updated)
+	return nil
+}
@@ -1,14 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kuduzow/team-4-pharmacy/internal/config"
+	"github.com/kuduzow/team-4-pharmacy/internal/jobs"
+	"github.com/kuduzow/team-4-pharmacy/internal/migrations"
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/notifications"
 	"github.com/kuduzow/team-4-pharmacy/internal/repository"
 	"github.com/kuduzow/team-4-pharmacy/internal/services"
+	"github.com/kuduzow/team-4-pharmacy/internal/storage"
 	"github.com/kuduzow/team-4-pharmacy/internal/transport"
 )
 
@@ -22,17 +35,51 @@ func main() {
 		&models.Cart{},
 		&models.CartItem{},
 		&models.Category{},
+		&models.Subcategory{},
+		&models.Manufacturer{},
+		&models.ManufacturerAlias{},
+		&models.Product{},
 		&models.Medicine{},
+		&models.Branch{},
 		&models.Order{},
 		&models.Payment{},
 		&models.Promocode{},
 		&models.Review{},
+		&models.ReviewVote{},
 		&models.User{},
+		&models.ActiveIngredient{},
+		&models.MedicineIngredient{},
+		&models.Batch{},
+		&models.OrderItem{},
+		&models.WriteOff{},
+		&models.WriteOffItem{},
+		&models.StockMovement{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderLine{},
+		&models.GoodsReceipt{},
+		&models.GoodsReceiptItem{},
+		&models.ImportJob{},
+		&models.ImportRowError{},
+		&models.MedicineImage{},
+		&models.PriceChange{},
+		&models.ScheduledPrice{},
+		&models.MedicineBarcode{},
+		&models.MedicineSubscription{},
+		&models.MedicineCoPurchase{},
+		&models.LowStockAlertState{},
 	); err != nil {
 		logger.Error("не удалось мигрировать базу данных", slog.Any("error", err))
 		os.Exit(1)
 	}
+
+	if err := migrations.Run(db); err != nil {
+		logger.Error("не удалось применить миграции", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	cartRepo := repository.NewCartRepository(db)
+	cartItemRepo := repository.NewCartItemRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	medicineRepo := repository.NewMedicineRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
@@ -40,16 +87,89 @@ func main() {
 	promocodeRepo := repository.NewPromocodeRepository(db)
 	reviewRepo := repository.NewReviewRepository(db)
 	userRepo := repository.NewUserRepository(db)
+	activeIngredientRepo := repository.NewActiveIngredientRepository(db)
+	interactionRepo := loadInteractionRepository(logger)
+	batchRepo := repository.NewBatchRepository(db)
+	writeOffRepo := repository.NewWriteOffRepository(db)
+	stockMovementRepo := repository.NewStockMovementRepository(db)
+	branchRepo := repository.NewBranchRepository(db)
+	lowStockAlertRepo := repository.NewLowStockAlertRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db)
+	importJobRepo := repository.NewImportJobRepository(db)
+	medicineImageRepo := repository.NewMedicineImageRepository(db)
+	priceRepo := repository.NewPriceRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	manufacturerRepo := repository.NewManufacturerRepository(db)
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	recommendationRepo := repository.NewRecommendationRepository(db)
+
+	mediaStorage, err := setupStorage()
+	if err != nil {
+		logger.Error("не удалось подготовить хранилище файлов", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	notifier := setupNotifier(logger)
 
-	cartService := services.NewCartService(cartRepo)
-	orderService := services.NewOrderService(orderRepo, paymentRepo)
+	interactionService := services.NewInteractionService(
+		activeIngredientRepo,
+		interactionRepo,
+		loadInteractionBlockSeverity(logger),
+	)
+	cartService := services.NewCartService(cartRepo, interactionService)
+	cartItemService := services.NewCartItemService(cartRepo, cartItemRepo, medicineRepo, db)
+	orderService := services.NewOrderService(orderRepo, paymentRepo, cartRepo, branchRepo, interactionService)
 	categoryService := services.NewCategoryService(categoryRepo)
-	medicineService := services.NewMedicineService(medicineRepo, categoryRepo)
+	medicineImageService := services.NewMedicineImageService(medicineImageRepo, medicineRepo, mediaStorage)
+	subscriptionService := services.NewSubscriptionService(subscriptionRepo, medicineRepo, userRepo, notifier)
+	medicineService := services.NewMedicineService(medicineRepo, categoryRepo, batchRepo, medicineImageService, productRepo, manufacturerRepo)
 
 	paymentService := services.NewPaymentService(paymentRepo)
 	promocodeService := services.NewPromocodeService(promocodeRepo)
-	reviewService := services.NewReviewService(reviewRepo)
+	reviewService := services.NewReviewService(reviewRepo, userRepo, loadReviewFilter(logger))
 	userService := services.NewUserService(userRepo)
+	activeIngredientService := services.NewActiveIngredientService(activeIngredientRepo, medicineRepo)
+	batchService := services.NewBatchService(batchRepo, medicineRepo, branchRepo)
+	inventoryService := services.NewInventoryService(batchRepo, writeOffRepo, stockMovementRepo, medicineRepo, lowStockAlertRepo)
+	branchService := services.NewBranchService(branchRepo, medicineRepo)
+	supplierService := services.NewSupplierService(supplierRepo)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo, supplierRepo, medicineRepo, branchRepo)
+	catalogService := services.NewCatalogService(medicineService, medicineRepo, categoryRepo, importJobRepo)
+	priceService := services.NewPriceService(priceRepo, medicineRepo)
+	manufacturerService := services.NewManufacturerService(manufacturerRepo)
+	recommendationService := services.NewRecommendationService(recommendationRepo, medicineRepo, medicineImageService)
+	productService := services.NewProductService(productRepo, medicineRepo, categoryRepo, medicineService, medicineImageService, manufacturerRepo)
+
+	if failed, err := catalogService.FailInterruptedImports(); err != nil {
+		logger.Error("не удалось завершить прерванные импорты", slog.String("error", err.Error()))
+	} else if failed > 0 {
+		logger.Warn("прерванные импорты помечены как failed", slog.Int64("count", failed))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var jobsWG sync.WaitGroup
+
+	warnDays := getIntEnv("EXPIRY_WARNING_DAYS", services.DefaultExpiringDays)
+	if warnDays > services.MaxExpiringDays {
+		warnDays = services.MaxExpiringDays
+	}
+	expiryJob := jobs.NewExpiryJob(inventoryService, getDurationEnv("EXPIRY_CHECK_INTERVAL", time.Hour), warnDays)
+	startJob(ctx, &jobsWG, expiryJob.Run)
+
+	lowStockJob := jobs.NewLowStockJob(inventoryService, notifier, getDurationEnv("LOW_STOCK_CHECK_INTERVAL", time.Hour))
+	startJob(ctx, &jobsWG, lowStockJob.Run)
+
+	priceJob := jobs.NewPriceJob(priceService, getDurationEnv("PRICE_SCHEDULE_INTERVAL", time.Minute))
+	startJob(ctx, &jobsWG, priceJob.Run)
+
+	subscriptionJob := jobs.NewSubscriptionJob(subscriptionService, getDurationEnv("SUBSCRIPTION_CHECK_INTERVAL", time.Minute))
+	startJob(ctx, &jobsWG, subscriptionJob.Run)
+
+	recommendationJob := jobs.NewRecommendationJob(recommendationService, getDurationEnv("RECOMMENDATION_REBUILD_INTERVAL", time.Hour))
+	startJob(ctx, &jobsWG, recommendationJob.Run)
 
 	router := gin.Default()
 
@@ -63,6 +183,20 @@ func main() {
 		reviewService,
 		userService,
 		cartService,
+		activeIngredientService,
+		batchService,
+		inventoryService,
+		branchService,
+		supplierService,
+		purchaseOrderService,
+		catalogService,
+		medicineImageService,
+		priceService,
+		productService,
+		cartItemService,
+		manufacturerService,
+		subscriptionService,
+		recommendationService,
 	)
 
 	addr := getServerAddress()
@@ -71,10 +205,33 @@ func main() {
 		slog.String("addr", addr),
 		slog.String("env", env),
 	)
-	if err := router.Run(addr); err != nil {
-		logger.Error("не удалось запустить HTTP-сервер", slog.Any("error", err))
-		os.Exit(1)
+	server := &http.Server{Addr: addr, Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("не удалось запустить HTTP-сервер", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("получен сигнал остановки, сервер завершает работу")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("не удалось корректно остановить HTTP-сервер", slog.Any("error", err))
 	}
+
+	jobsWG.Wait()
+	logger.Info("фоновые задачи остановлены")
+}
+
+func startJob(ctx context.Context, wg *sync.WaitGroup, run func(context.Context)) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		run(ctx)
+	}()
 }
 func setupLogger() *slog.Logger {
 	var level slog.Level
@@ -108,6 +265,106 @@ func getServerAddress() string {
 	return ":" + port
 }
 
+func loadInteractionRepository(logger *slog.Logger) repository.InteractionRepository {
+	path := os.Getenv("INTERACTIONS_FILE")
+	if path == "" {
+		path = "interactions.json"
+	}
+
+	repo, err := repository.NewFileInteractionRepository(path)
+	if err != nil {
+		logger.Error("не удалось загрузить базу взаимодействий лекарств",
+			slog.String("path", path),
+			slog.Any("error", err),
+		)
+		os.Exit(1)
+	}
+
+	return repo
+}
+
+func loadInteractionBlockSeverity(logger *slog.Logger) models.InteractionSeverity {
+	raw := strings.TrimSpace(os.Getenv("INTERACTION_BLOCK_SEVERITY"))
+	if raw == "" {
+		return models.SeverityMajor
+	}
+
+	severity := models.InteractionSeverity(strings.ToLower(raw))
+	if !severity.Valid() {
+		logger.Warn("некорректный INTERACTION_BLOCK_SEVERITY, используется major",
+			slog.String("value", raw),
+		)
+		return models.SeverityMajor
+	}
+
+	return severity
+}
+
+func loadReviewFilter(logger *slog.Logger) *services.ReviewFilter {
+	path := os.Getenv("REVIEW_FILTER_FILE")
+	if path == "" {
+		path = "review_filter.json"
+	}
+
+	filter, err := services.LoadReviewFilter(path)
+	if err != nil {
+		logger.Warn("не удалось загрузить фильтр отзывов",
+			slog.String("path", path),
+			slog.Any("error", err),
+		)
+		return &services.ReviewFilter{}
+	}
+
+	return filter
+}
+
+func setupStorage() (storage.Storage, error) {
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+
+	baseURL := os.Getenv("MEDIA_BASE_URL")
+	if baseURL == "" {
+		baseURL = "/media"
+	}
+
+	return storage.NewLocalStorage(dir, baseURL)
+}
+
+func setupNotifier(logger *slog.Logger) notifications.Notifier {
+	if url := os.Getenv("NOTIFICATIONS_WEBHOOK_URL"); url != "" {
+		return notifications.NewWebhookNotifier(url)
+	}
+	return notifications.NewLogNotifier(logger)
+}
+
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
+
+func getIntEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
 func getEnvironment() string {
 	env := os.Getenv("ENVIRONMENT")
 	if env == "" {
@@ -0,0 +1,39 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+func Fit(src image.Image, maxSize int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= maxSize && height <= maxSize {
+		return src
+	}
+
+	dstWidth, dstHeight := maxSize, maxSize
+	if width > height {
+		dstHeight = max(1, height*maxSize/width)
+	} else {
+		dstWidth = max(1, width*maxSize/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func Flatten(src image.Image, background color.Color) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+
+	return dst
+}
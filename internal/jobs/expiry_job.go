@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type ExpiryJob struct {
+	inventory services.InventoryService
+	interval  time.Duration
+	warnDays  int
+	logger    *slog.Logger
+}
+
+func NewExpiryJob(inventory services.InventoryService, interval time.Duration, warnDays int) *ExpiryJob {
+	return &ExpiryJob{
+		inventory: inventory,
+		interval:  interval,
+		warnDays:  warnDays,
+		logger:    slog.Default(),
+	}
+}
+
+func (j *ExpiryJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.runOnce()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce()
+		}
+	}
+}
+
+func (j *ExpiryJob) runOnce() {
+	medicines, err := j.inventory.BlockExpiredBatches()
+	if err != nil {
+		j.logger.Error("expiry_job: failed to block expired batches", slog.String("error", err.Error()))
+		return
+	}
+	if medicines > 0 {
+		j.logger.Info("expiry_job: expired batches blocked", slog.Int("medicines", medicines))
+	}
+
+	expiring, err := j.inventory.ListExpiring(j.warnDays)
+	if err != nil {
+		j.logger.Error("expiry_job: failed to list expiring batches", slog.String("error", err.Error()))
+		return
+	}
+	if len(expiring) > 0 {
+		j.logger.Warn("expiry_job: batches expiring soon", slog.Int("count", len(expiring)), slog.Int("days", j.warnDays))
+	}
+}
@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/notifications"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type LowStockJob struct {
+	inventory services.InventoryService
+	notifier  notifications.Notifier
+	interval  time.Duration
+	logger    *slog.Logger
+}
+
+func NewLowStockJob(inventory services.InventoryService, notifier notifications.Notifier, interval time.Duration) *LowStockJob {
+	return &LowStockJob{
+		inventory: inventory,
+		notifier:  notifier,
+		interval:  interval,
+		logger:    slog.Default(),
+	}
+}
+
+func (j *LowStockJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.runOnce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce(ctx)
+		}
+	}
+}
+
+func (j *LowStockJob) runOnce(ctx context.Context) {
+	alerts, err := j.inventory.PendingLowStockAlerts()
+	if err != nil {
+		j.logger.Error("low_stock_job: failed to list low stock medicines", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, alert := range alerts {
+
+		err := j.notifier.Notify(ctx, notifications.Notification{
+			Type:    "low_stock",
+			Subject: "Заканчивается " + alert.Name,
+			Message: fmt.Sprintf("Остаток %d при точке заказа %d", alert.StockQuantity, alert.ReorderPoint),
+			Data: map[string]any{
+				"medicine_id":    alert.MedicineID,
+				"stock_quantity": alert.StockQuantity,
+				"reorder_point":  alert.ReorderPoint,
+				"target_stock":   alert.TargetStock,
+			},
+		})
+		if err != nil {
+			j.logger.Error("low_stock_job: failed to send alert",
+				slog.Uint64("medicine_id", uint64(alert.MedicineID)),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		if err := j.inventory.MarkLowStockAlerted(alert.MedicineID); err != nil {
+			j.logger.Error("low_stock_job: failed to save alert state",
+				slog.Uint64("medicine_id", uint64(alert.MedicineID)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
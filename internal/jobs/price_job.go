@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type PriceJob struct {
+	prices   services.PriceService
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewPriceJob(prices services.PriceService, interval time.Duration) *PriceJob {
+	return &PriceJob{
+		prices:   prices,
+		interval: interval,
+		logger:   slog.Default(),
+	}
+}
+
+func (j *PriceJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.runOnce()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce()
+		}
+	}
+}
+
+func (j *PriceJob) runOnce() {
+	applied, err := j.prices.ApplyScheduledPrices()
+	if err != nil {
+		j.logger.Error("price_job: failed to apply scheduled prices", slog.String("error", err.Error()))
+		return
+	}
+	if applied > 0 {
+		j.logger.Info("price_job: scheduled prices applied", slog.Int("count", applied))
+	}
+}
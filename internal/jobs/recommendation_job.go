@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type RecommendationJob struct {
+	recommendations services.RecommendationService
+	interval        time.Duration
+	logger          *slog.Logger
+}
+
+func NewRecommendationJob(recommendations services.RecommendationService, interval time.Duration) *RecommendationJob {
+	return &RecommendationJob{
+		recommendations: recommendations,
+		interval:        interval,
+		logger:          slog.Default(),
+	}
+}
+
+func (j *RecommendationJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.runOnce()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce()
+		}
+	}
+}
+
+func (j *RecommendationJob) runOnce() {
+	pairs, err := j.recommendations.RebuildCoPurchases()
+	if err != nil {
+		j.logger.Error("recommendation_job: failed to rebuild co-purchase statistics", slog.String("error", err.Error()))
+		return
+	}
+	j.logger.Info("recommendation_job: co-purchase statistics rebuilt", slog.Int64("pairs", pairs))
+}
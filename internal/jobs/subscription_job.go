@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type SubscriptionJob struct {
+	subscriptions services.SubscriptionService
+	interval      time.Duration
+	logger        *slog.Logger
+}
+
+func NewSubscriptionJob(subscriptions services.SubscriptionService, interval time.Duration) *SubscriptionJob {
+	return &SubscriptionJob{
+		subscriptions: subscriptions,
+		interval:      interval,
+		logger:        slog.Default(),
+	}
+}
+
+func (j *SubscriptionJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.runOnce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce(ctx)
+		}
+	}
+}
+
+func (j *SubscriptionJob) runOnce(ctx context.Context) {
+	sent, err := j.subscriptions.NotifyDue(ctx)
+	if err != nil {
+		j.logger.Error("subscription_job: failed to notify subscribers", slog.String("error", err.Error()))
+		return
+	}
+	if sent > 0 {
+		j.logger.Info("subscription_job: notifications sent", slog.Int("count", sent))
+	}
+}
@@ -0,0 +1,204 @@
+package migrations
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type migration struct {
+	name       string
+	statements []string
+}
+
+var migrations = []migration{
+	{
+		name: "medicines_search_vector",
+		statements: []string{
+			`ALTER TABLE medicines ADD COLUMN IF NOT EXISTS search_vector tsvector
+				GENERATED ALWAYS AS (
+					setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+					setweight(to_tsvector('russian', coalesce(manufacturer, '')), 'B') ||
+					setweight(to_tsvector('russian', coalesce(description, '')), 'C')
+				) STORED`,
+			`CREATE INDEX IF NOT EXISTS idx_medicines_search_vector ON medicines USING GIN (search_vector)`,
+		},
+	},
+	{
+		name: "trigram_suggest_indexes",
+		statements: []string{
+			`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+			`CREATE INDEX IF NOT EXISTS idx_medicines_name_trgm ON medicines USING GIN (name gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_medicines_manufacturer_trgm ON medicines USING GIN (manufacturer gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING GIN (name gin_trgm_ops)`,
+		},
+	},
+	{
+		name: "medicine_products",
+		statements: []string{
+			`INSERT INTO products (id, created_at, updated_at, name, description, manufacturer, category_id, subcategory_id)
+				SELECT m.id, m.created_at, m.updated_at, m.name, m.description, m.manufacturer, m.category_id, m.subcategory_id
+				FROM medicines m
+				WHERE m.product_id IS NULL AND m.deleted_at IS NULL
+					AND NOT EXISTS (SELECT 1 FROM products p WHERE p.id = m.id)`,
+			`UPDATE medicines SET product_id = id
+				WHERE product_id IS NULL AND EXISTS (SELECT 1 FROM products p WHERE p.id = medicines.id)`,
+			`SELECT setval(pg_get_serial_sequence('products', 'id'), greatest((SELECT max(id) FROM products), 1))`,
+		},
+	},
+	{
+		name: "normalize_manufacturers",
+		statements: []string{
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_manufacturers_lower_name ON manufacturers (lower(name)) WHERE deleted_at IS NULL`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_manufacturer_aliases_lower_name ON manufacturer_aliases (lower(name))`,
+			`INSERT INTO manufacturers (created_at, updated_at, name)
+				SELECT now(), now(), min(trim(m.manufacturer))
+				FROM medicines m
+				WHERE m.deleted_at IS NULL AND m.manufacturer_id IS NULL AND trim(m.manufacturer) <> ''
+					AND NOT EXISTS (SELECT 1 FROM manufacturers f WHERE f.deleted_at IS NULL AND lower(f.name) = lower(trim(m.manufacturer)))
+					AND NOT EXISTS (SELECT 1 FROM manufacturer_aliases a WHERE lower(a.name) = lower(trim(m.manufacturer)))
+				GROUP BY lower(trim(m.manufacturer))`,
+			`UPDATE medicines SET manufacturer_id = coalesce(
+					(SELECT f.id FROM manufacturers f WHERE f.deleted_at IS NULL AND lower(f.name) = lower(trim(medicines.manufacturer))),
+					(SELECT a.manufacturer_id FROM manufacturer_aliases a WHERE lower(a.name) = lower(trim(medicines.manufacturer))))
+				WHERE manufacturer_id IS NULL AND trim(manufacturer) <> ''`,
+			`UPDATE products SET manufacturer_id = coalesce(
+					(SELECT f.id FROM manufacturers f WHERE f.deleted_at IS NULL AND lower(f.name) = lower(trim(products.manufacturer))),
+					(SELECT a.manufacturer_id FROM manufacturer_aliases a WHERE lower(a.name) = lower(trim(products.manufacturer))))
+				WHERE manufacturer_id IS NULL AND trim(manufacturer) <> ''`,
+			`UPDATE medicines SET manufacturer = f.name FROM manufacturers f
+				WHERE f.id = medicines.manufacturer_id AND medicines.manufacturer <> f.name`,
+			`UPDATE products SET manufacturer = f.name FROM manufacturers f
+				WHERE f.id = products.manufacturer_id AND products.manufacturer <> f.name`,
+		},
+	},
+	{
+		name: "unique_verified_reviews",
+		statements: []string{
+			`UPDATE reviews SET deleted_at = now()
+				WHERE deleted_at IS NULL AND EXISTS (
+					SELECT 1 FROM reviews newer
+					WHERE newer.deleted_at IS NULL AND newer.user_id = reviews.user_id
+						AND newer.medicine_id = reviews.medicine_id AND newer.id > reviews.id)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_user_medicine ON reviews (user_id, medicine_id) WHERE deleted_at IS NULL`,
+			`UPDATE reviews SET verified_purchase = true
+				WHERE NOT verified_purchase AND EXISTS (
+					SELECT 1 FROM orders o JOIN order_items i ON i.order_id = o.id AND i.deleted_at IS NULL
+					WHERE o.deleted_at IS NULL AND o.order_status = 'completed'
+						AND o.user_id = reviews.user_id AND i.medicine_id = reviews.medicine_id)`,
+		},
+	},
+	{
+		name: "stock_opening_balances",
+		statements: []string{
+			`INSERT INTO stock_movements (created_at, medicine_id, type, quantity, balance_after, actor, reason)
+				SELECT coalesce(f.created_at - interval '1 second', now()), m.id, 'adjustment', o.opening, o.opening, 'system', 'входящий остаток'
+				FROM medicines m
+				LEFT JOIN LATERAL (
+					SELECT created_at, quantity, balance_after FROM stock_movements
+					WHERE medicine_id = m.id ORDER BY id LIMIT 1
+				) AS f ON true
+				CROSS JOIN LATERAL (
+					SELECT CASE
+						WHEN f.quantity IS NOT NULL THEN f.balance_after - f.quantity
+						WHEN EXISTS (SELECT 1 FROM batches b WHERE b.medicine_id = m.id AND b.deleted_at IS NULL)
+							THEN (SELECT sum(b.quantity) FROM batches b WHERE b.medicine_id = m.id AND b.deleted_at IS NULL)
+						ELSE m.stock_quantity
+					END AS opening
+				) AS o
+				WHERE m.deleted_at IS NULL AND o.opening <> 0`,
+		},
+	},
+	{
+		name: "assign_single_branch_batches",
+		statements: []string{
+			`UPDATE batches SET branch_id = (SELECT min(id) FROM branches WHERE deleted_at IS NULL)
+				WHERE branch_id IS NULL
+					AND (SELECT count(*) FROM branches WHERE deleted_at IS NULL) = 1`,
+			`UPDATE purchase_orders SET branch_id = (SELECT min(id) FROM branches WHERE deleted_at IS NULL)
+				WHERE branch_id IS NULL
+					AND (SELECT count(*) FROM branches WHERE deleted_at IS NULL) = 1`,
+		},
+	},
+	{
+		name: "release_deleted_medicine_barcodes",
+		statements: []string{
+			`DELETE FROM medicine_barcodes
+				WHERE medicine_id IN (SELECT id FROM medicines WHERE deleted_at IS NOT NULL)`,
+		},
+	},
+	{
+		name: "medicine_review_stats",
+		statements: []string{
+			`UPDATE medicines SET
+					avg_rating = coalesce((SELECT round(avg(r.rating)::numeric, 2) FROM reviews r WHERE r.medicine_id = medicines.id AND r.status = 'approved' AND r.deleted_at IS NULL), 0),
+					review_count = (SELECT count(*) FROM reviews r WHERE r.medicine_id = medicines.id AND r.status = 'approved' AND r.deleted_at IS NULL)`,
+		},
+	},
+	{
+		name: "manufacturer_trigram_indexes",
+		statements: []string{
+			`CREATE INDEX IF NOT EXISTS idx_manufacturers_name_trgm ON manufacturers USING GIN (name gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_manufacturer_aliases_name_trgm ON manufacturer_aliases USING GIN (name gin_trgm_ops)`,
+		},
+	},
+	{
+		name: "sync_variant_product_fields",
+		statements: []string{
+			`UPDATE medicines m SET
+					description = p.description,
+					manufacturer = p.manufacturer,
+					manufacturer_id = p.manufacturer_id,
+					category_id = p.category_id,
+					subcategory_id = p.subcategory_id
+				FROM products p
+				WHERE m.product_id = p.id
+					AND (m.description, m.manufacturer, m.manufacturer_id, m.category_id, m.subcategory_id)
+						IS DISTINCT FROM (p.description, p.manufacturer, p.manufacturer_id, p.category_id, p.subcategory_id)`,
+		},
+	},
+}
+
+func Run(db *gorm.DB) error {
+	logger := slog.Default()
+
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		name text PRIMARY KEY,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("таблица schema_migrations: %w", err)
+	}
+
+	var applied []string
+	if err := db.Table("schema_migrations").Pluck("name", &applied).Error; err != nil {
+		return fmt.Errorf("таблица schema_migrations: %w", err)
+	}
+
+	done := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		done[name] = true
+	}
+
+	for _, m := range migrations {
+		if done[m.name] {
+			continue
+		}
+
+		logger.Info("применение миграции", slog.String("name", m.name))
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, stmt := range m.statements {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Exec(`INSERT INTO schema_migrations (name) VALUES (?)`, m.name).Error
+		})
+		if err != nil {
+			return fmt.Errorf("миграция %s: %w", m.name, err)
+		}
+	}
+
+	return nil
+}
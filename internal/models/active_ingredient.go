@@ -0,0 +1,32 @@
+package models
+
+import "gorm.io/gorm"
+
+type ActiveIngredient struct {
+	gorm.Model
+	Name string `json:"name" gorm:"not null;uniqueIndex"`
+}
+
+type MedicineIngredient struct {
+	MedicineID         uint              `json:"medicine_id" gorm:"primaryKey"`
+	ActiveIngredientID uint              `json:"active_ingredient_id" gorm:"primaryKey;index"`
+	ActiveIngredient   *ActiveIngredient `json:"active_ingredient,omitempty"`
+	Strength           string            `json:"strength"`
+	DosageForm         string            `json:"dosage_form"`
+}
+
+type ActiveIngredientCreateRequest struct {
+	Name string `json:"name"`
+}
+
+type MedicineIngredientRequest struct {
+	ActiveIngredientID uint   `json:"active_ingredient_id"`
+	Strength           string `json:"strength"`
+	DosageForm         string `json:"dosage_form"`
+}
+
+type MedicineAnalog struct {
+	Medicine          Medicine `json:"medicine"`
+	ExactMatch        bool     `json:"exact_match"`
+	SharedIngredients int      `json:"shared_ingredients"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Batch struct {
+	gorm.Model
+	MedicineID uint       `json:"medicine_id" gorm:"not null;index"`
+	Medicine   *Medicine  `json:"-"`
+	BranchID   *uint      `json:"branch_id" gorm:"index"`
+	Branch     *Branch    `json:"-"`
+	LotNumber  string     `json:"lot_number" gorm:"not null"`
+	ExpiryDate time.Time  `json:"expiry_date" gorm:"not null;index"`
+	Quantity   int        `json:"quantity"`
+	ReceivedAt time.Time  `json:"received_at"`
+	BlockedAt  *time.Time `json:"blocked_at"`
+}
+
+type WriteOff struct {
+	gorm.Model
+	Reason string         `json:"reason" gorm:"not null"`
+	Items  []WriteOffItem `json:"items"`
+}
+
+type WriteOffItem struct {
+	gorm.Model
+	WriteOffID   uint      `json:"write_off_id" gorm:"not null;index"`
+	BatchID      uint      `json:"batch_id" gorm:"not null;index"`
+	MedicineID   uint      `json:"medicine_id" gorm:"not null;index"`
+	MedicineName string    `json:"medicine_name"`
+	LotNumber    string    `json:"lot_number"`
+	ExpiryDate   time.Time `json:"expiry_date"`
+	Quantity     int       `json:"quantity"`
+}
+
+type WriteOffCreateRequest struct {
+	Reason     string `json:"reason"`
+	MedicineID *uint  `json:"medicine_id"`
+	Actor      string `json:"actor"`
+}
+
+type BatchCreateRequest struct {
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate time.Time  `json:"expiry_date"`
+	Quantity   int        `json:"quantity"`
+	ReceivedAt *time.Time `json:"received_at"`
+	BranchID   *uint      `json:"branch_id"`
+	Actor      string     `json:"actor"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "gorm.io/gorm"
+
+type Branch struct {
+	gorm.Model
+	Name      string  `json:"name" gorm:"not null"`
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type BranchCreateRequest struct {
+	Name      string  `json:"name"`
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type BranchUpdateRequest struct {
+	Name      *string  `json:"name"`
+	Address   *string  `json:"address"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+type BranchAvailability struct {
+	BranchID   uint     `json:"branch_id"`
+	Name       string   `json:"name"`
+	Address    string   `json:"address"`
+	Latitude   float64  `json:"latitude"`
+	Longitude  float64  `json:"longitude"`
+	Quantity   int      `json:"quantity"`
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+}
@@ -12,3 +12,10 @@ type Cart struct {
 type UpdateCart struct {
 	UserID uint `json:"user_id"`
 }
+
+type CartResponse struct {
+	UserID       uint                 `json:"user_id"`
+	Items        []CartItem           `json:"items"`
+	TotalPrice   int64                `json:"total_price"`
+	Interactions []InteractionWarning `json:"interactions"`
+}
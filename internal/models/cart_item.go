@@ -13,8 +13,9 @@ type CartItem struct {
 }
 
 type CartCreateItemRequest struct {
-	MedicineID uint  `json:"medicine_id"`
-	Quantity   int64 `json:"quantity"`
+	MedicineID uint   `json:"medicine_id"`
+	Barcode    string `json:"barcode"`
+	Quantity   int64  `json:"quantity"`
 }
 
 type UpdateCartItemRequest struct {
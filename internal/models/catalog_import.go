@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobStatus string
+
+const (
+	ImportJobPending   ImportJobStatus = "pending"
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobCompleted ImportJobStatus = "completed"
+	ImportJobFailed    ImportJobStatus = "failed"
+)
+
+type ImportJob struct {
+	gorm.Model
+	FileName   string           `json:"file_name"`
+	Format     string           `json:"format"`
+	Status     ImportJobStatus  `json:"status" gorm:"not null;default:pending"`
+	TotalRows  int              `json:"total_rows"`
+	Created    int              `json:"created"`
+	Updated    int              `json:"updated"`
+	Failed     int              `json:"failed"`
+	Error      string           `json:"error,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at"`
+	Errors     []ImportRowError `json:"errors,omitempty"`
+}
+
+type ImportRowError struct {
+	ID          uint   `json:"-" gorm:"primaryKey"`
+	ImportJobID uint   `json:"-" gorm:"not null;index"`
+	Row         int    `json:"row"`
+	SKU         string `json:"sku"`
+	Message     string `json:"message"`
+}
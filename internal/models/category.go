@@ -31,3 +31,16 @@ type UpdateSubcategory struct {
 	CategoryID *uint   `json:"category_id"`
 	Name       *string `json:"name"`
 }
+
+type SubcategoryTreeNode struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	MedicineCount int64  `json:"medicine_count"`
+}
+
+type CategoryTreeNode struct {
+	ID            uint                  `json:"id"`
+	Name          string                `json:"name"`
+	MedicineCount int64                 `json:"medicine_count"`
+	Subcategories []SubcategoryTreeNode `json:"subcategories"`
+}
@@ -0,0 +1,45 @@
+package models
+
+type InteractionSeverity string
+
+const (
+	SeverityMinor           InteractionSeverity = "minor"
+	SeverityModerate        InteractionSeverity = "moderate"
+	SeverityMajor           InteractionSeverity = "major"
+	SeverityContraindicated InteractionSeverity = "contraindicated"
+)
+
+var severityRanks = map[InteractionSeverity]int{
+	SeverityMinor:           1,
+	SeverityModerate:        2,
+	SeverityMajor:           3,
+	SeverityContraindicated: 4,
+}
+
+func (s InteractionSeverity) Rank() int {
+	return severityRanks[s]
+}
+
+func (s InteractionSeverity) Valid() bool {
+	_, ok := severityRanks[s]
+	return ok
+}
+
+type DrugInteraction struct {
+	IngredientA string              `json:"ingredient_a"`
+	IngredientB string              `json:"ingredient_b"`
+	Severity    InteractionSeverity `json:"severity"`
+	Description string              `json:"description"`
+}
+
+type InteractionWarning struct {
+	MedicineAID    uint                `json:"medicine_a_id"`
+	MedicineAName  string              `json:"medicine_a_name"`
+	MedicineBID    uint                `json:"medicine_b_id"`
+	MedicineBName  string              `json:"medicine_b_name"`
+	IngredientA    string              `json:"ingredient_a"`
+	IngredientB    string              `json:"ingredient_b"`
+	Severity       InteractionSeverity `json:"severity"`
+	Description    string              `json:"description"`
+	BlocksCheckout bool                `json:"blocks_checkout"`
+}
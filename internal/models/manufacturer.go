@@ -0,0 +1,32 @@
+package models
+
+import "gorm.io/gorm"
+
+type Manufacturer struct {
+	gorm.Model
+	Name    string              `json:"name" gorm:"not null"`
+	Country string              `json:"country"`
+	Aliases []ManufacturerAlias `json:"aliases"`
+}
+
+type ManufacturerAlias struct {
+	ID             uint   `json:"id" gorm:"primaryKey"`
+	ManufacturerID uint   `json:"manufacturer_id" gorm:"not null;index"`
+	Name           string `json:"name" gorm:"not null"`
+}
+
+type ManufacturerCreateRequest struct {
+	Name    string   `json:"name"`
+	Country string   `json:"country"`
+	Aliases []string `json:"aliases"`
+}
+
+type ManufacturerUpdateRequest struct {
+	Name    *string   `json:"name"`
+	Country *string   `json:"country"`
+	Aliases *[]string `json:"aliases"`
+}
+
+type ManufacturerMergeRequest struct {
+	SourceIDs []uint `json:"source_ids"`
+}
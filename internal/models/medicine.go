@@ -4,40 +4,113 @@ import "gorm.io/gorm"
 
 type Medicine struct {
 	gorm.Model
-	Name                 string       `json:"name"`
-	Description          string       `json:"description"`
-	Price                float64      `json:"price"`
-	InStock              bool         `json:"in_stock"`
-	StockQuantity        int          `json:"stock_quantity"`
-	CategoryID           uint         `json:"category_id" gorm:"not null;index"`
-	Category             *Category    `json:"-"`
-	SubcategoryID        uint         `json:"subcategory_id" gorm:"not null;index"`
-	Subcategory          *Subcategory `json:"-"`
-	Manufacturer         string       `json:"manufacturer"`
-	PrescriptionRequired bool         `json:"prescription_required"`
-	AvgRating            float64      `json:"avg_rating"`
+	SKU                  *string           `json:"sku" gorm:"uniqueIndex"`
+	ProductID            *uint             `json:"product_id" gorm:"index"`
+	Name                 string            `json:"name"`
+	Description          string            `json:"description"`
+	Price                float64           `json:"price"`
+	InStock              bool              `json:"in_stock"`
+	StockQuantity        int               `json:"stock_quantity"`
+	CategoryID           uint              `json:"category_id" gorm:"not null;index"`
+	Category             *Category         `json:"-"`
+	SubcategoryID        uint              `json:"subcategory_id" gorm:"not null;index"`
+	Subcategory          *Subcategory      `json:"-"`
+	Manufacturer         string            `json:"manufacturer"`
+	ManufacturerID       *uint             `json:"manufacturer_id" gorm:"index"`
+	PrescriptionRequired bool              `json:"prescription_required"`
+	AvgRating            float64           `json:"avg_rating"`
+	ReviewCount          int64             `json:"review_count"`
+	ReorderPoint         int               `json:"reorder_point"`
+	TargetStock          int               `json:"target_stock"`
+	Strength             string            `json:"strength"`
+	DosageForm           string            `json:"dosage_form"`
+	PackSize             int               `json:"pack_size"`
+	Images               []MedicineImage   `json:"images,omitempty"`
+	Barcodes             []MedicineBarcode `json:"barcodes,omitempty"`
+	SearchRank           float64           `json:"search_rank,omitempty" gorm:"->;-:migration"`
+	NameHighlight        string            `json:"name_highlight,omitempty" gorm:"->;-:migration"`
+	DescriptionHighlight string            `json:"description_highlight,omitempty" gorm:"->;-:migration"`
+	LocalStock           *int              `json:"local_stock,omitempty" gorm:"->;-:migration"`
 }
 
 type MedicineCreateRequest struct {
-	Name                 string  `json:"name"`
-	Description          string  `json:"description"`
-	Price                float64 `json:"price"`
-	InStock              bool    `json:"in_stock"`
-	StockQuantity        int     `json:"stock_quantity"`
-	CategoryID           uint    `json:"category_id"`
-	SubcategoryID        uint    `json:"subcategory_id"`
-	Manufacturer         string  `json:"manufacturer"`
-	PrescriptionRequired bool    `json:"prescription_required"`
-	AvgRating            float64 `json:"avg_rating"`
+	SKU                  string   `json:"sku"`
+	ProductID            *uint    `json:"product_id"`
+	Strength             string   `json:"strength"`
+	DosageForm           string   `json:"dosage_form"`
+	PackSize             int      `json:"pack_size"`
+	Barcodes             []string `json:"barcodes"`
+	Name                 string   `json:"name"`
+	Description          string   `json:"description"`
+	Price                float64  `json:"price"`
+	InStock              bool     `json:"in_stock"`
+	StockQuantity        int      `json:"stock_quantity"`
+	CategoryID           uint     `json:"category_id"`
+	SubcategoryID        uint     `json:"subcategory_id"`
+	Manufacturer         string   `json:"manufacturer"`
+	ManufacturerID       *uint    `json:"manufacturer_id"`
+	PrescriptionRequired bool     `json:"prescription_required"`
+	ReorderPoint         int      `json:"reorder_point"`
+	TargetStock          int      `json:"target_stock"`
+	Actor                string   `json:"actor"`
 }
 
 type MedicineUpdateRequest struct {
-	Name                 *string  `json:"name"`
-	Description          *string  `json:"description"`
-	Price                *float64 `json:"price"`
-	InStock              *bool    `json:"in_stock"`
-	StockQuantity        *int     `json:"stock_quantity"`
-	Manufacturer         *string  `json:"manufacturer"`
-	PrescriptionRequired *bool    `json:"prescription_required"`
-	AvgRating            *float64 `json:"avg_rating"`
+	Name                 *string   `json:"name"`
+	Description          *string   `json:"description"`
+	Price                *float64  `json:"price"`
+	StockQuantity        *int      `json:"stock_quantity"`
+	Manufacturer         *string   `json:"manufacturer"`
+	ManufacturerID       *uint     `json:"manufacturer_id"`
+	PrescriptionRequired *bool     `json:"prescription_required"`
+	ReorderPoint         *int      `json:"reorder_point"`
+	TargetStock          *int      `json:"target_stock"`
+	Actor                string    `json:"actor"`
+	Strength             *string   `json:"strength"`
+	DosageForm           *string   `json:"dosage_form"`
+	PackSize             *int      `json:"pack_size"`
+	Barcodes             *[]string `json:"barcodes"`
+	StockReason          string    `json:"stock_reason"`
+	PriceReason          string    `json:"price_reason"`
+}
+
+type MedicineListResponse struct {
+	Items         []Medicine `json:"items"`
+	Total         int64      `json:"total"`
+	NextPageToken string     `json:"next_page_token,omitempty"`
+}
+
+type SuggestionType string
+
+const (
+	SuggestionTypeMedicine     SuggestionType = "medicine"
+	SuggestionTypeManufacturer SuggestionType = "manufacturer"
+	SuggestionTypeCategory     SuggestionType = "category"
+)
+
+type MedicineSuggestion struct {
+	Type  SuggestionType `json:"type"`
+	ID    uint           `json:"id,omitempty"`
+	Text  string         `json:"text"`
+	Score float64        `json:"score"`
+}
+
+type FacetCount struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+type PriceBucketCount struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to"`
+	Count int64    `json:"count"`
+}
+
+type MedicineFacets struct {
+	Categories           []FacetCount       `json:"categories"`
+	Subcategories        []FacetCount       `json:"subcategories"`
+	Manufacturers        []FacetCount       `json:"manufacturers"`
+	PrescriptionRequired []FacetCount       `json:"prescription_required"`
+	InStock              []FacetCount       `json:"in_stock"`
+	PriceBuckets         []PriceBucketCount `json:"price_buckets"`
 }
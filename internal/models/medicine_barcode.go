@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type MedicineBarcode struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+	MedicineID uint      `json:"medicine_id" gorm:"not null;index"`
+	Code       string    `json:"code" gorm:"size:14;not null;uniqueIndex"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+type MedicineImage struct {
+	gorm.Model
+	MedicineID  uint              `json:"medicine_id" gorm:"not null;index"`
+	Position    int               `json:"position"`
+	IsPrimary   bool              `json:"is_primary"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	StorageKey  string            `json:"-" gorm:"not null"`
+	URL         string            `json:"url" gorm:"-"`
+	Thumbnails  map[string]string `json:"thumbnails,omitempty" gorm:"-"`
+}
+
+type MedicineImageOrderRequest struct {
+	ImageIDs []uint `json:"image_ids"`
+}
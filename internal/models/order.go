@@ -22,14 +22,19 @@ type Order struct {
 	FinalPrice      int         `json:"final_price"`
 	DeliveryAddress string      `json:"delivery_address"`
 	Comment         string      `json:"comment"`
+	BranchID        *uint       `json:"branch_id" gorm:"index"`
+	Items           []OrderItem `json:"items"`
 }
 type OrderItem struct {
-	OrderID      uint   `json:"order_id"`
-	MedicineID   uint   `json:"medicine_id"`
+	gorm.Model
+	OrderID      uint   `json:"order_id" gorm:"not null;index"`
+	MedicineID   uint   `json:"medicine_id" gorm:"not null;index"`
 	MedicineName string `json:"medicine_name"`
 	Quantity     int    `json:"quantity"`
-	PricePerUnit uint   `json:"price_per_uint"`
-	LineTotal    string `json:"line_total"`
+	PricePerUnit int64  `json:"price_per_uint"`
+	LineTotal    int64  `json:"line_total,string"`
+	BatchID      *uint  `json:"batch_id" gorm:"index"`
+	LotNumber    string `json:"lot_number"`
 }
 
 type OrderCreate struct {
@@ -41,6 +46,9 @@ type OrderCreate struct {
 	FinalPrice      int         `json:"final_price"`
 	DeliveryAddress string      `json:"delivery_address"`
 	Comment         string      `json:"comment"`
+	BranchID        *uint       `json:"branch_id"`
+
+	AcknowledgeInteractions bool `json:"acknowledge_interactions"`
 }
 type OrderUpdate struct {
 	
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PriceChangeSource string
+
+const (
+	PriceSourceManual   PriceChangeSource = "manual"
+	PriceSourceSchedule PriceChangeSource = "schedule"
+	PriceSourceImport   PriceChangeSource = "import"
+	PriceSourceInitial  PriceChangeSource = "initial"
+)
+
+type PriceChange struct {
+	ID         uint              `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time         `json:"created_at" gorm:"index"`
+	MedicineID uint              `json:"medicine_id" gorm:"not null;index"`
+	OldPrice   float64           `json:"old_price"`
+	NewPrice   float64           `json:"new_price"`
+	Source     PriceChangeSource `json:"source"`
+	Actor      string            `json:"actor"`
+	Reason     string            `json:"reason"`
+}
+
+type ScheduledPrice struct {
+	gorm.Model
+	MedicineID    uint       `json:"medicine_id" gorm:"not null;index"`
+	Price         float64    `json:"price"`
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"not null;index"`
+	AppliedAt     *time.Time `json:"applied_at"`
+	Actor         string     `json:"actor"`
+	Reason        string     `json:"reason"`
+}
+
+type ScheduledPriceRequest struct {
+	Price         float64   `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	Actor         string    `json:"actor"`
+	Reason        string    `json:"reason"`
+}
+
+type PriceDrop struct {
+	PreviousPrice float64   `json:"previous_price"`
+	CurrentPrice  float64   `json:"current_price"`
+	Percent       float64   `json:"percent"`
+	DroppedAt     time.Time `json:"dropped_at"`
+}
+
+type PriceHistoryResponse struct {
+	MedicineID   uint             `json:"medicine_id"`
+	CurrentPrice float64          `json:"current_price"`
+	PriceDrop    *PriceDrop       `json:"price_drop"`
+	Scheduled    []ScheduledPrice `json:"scheduled"`
+	Items        []PriceChange    `json:"items"`
+	Total        int64            `json:"total"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "gorm.io/gorm"
+
+type Product struct {
+	gorm.Model
+	Name           string     `json:"name" gorm:"not null"`
+	Description    string     `json:"description"`
+	Manufacturer   string     `json:"manufacturer"`
+	ManufacturerID *uint      `json:"manufacturer_id" gorm:"index"`
+	CategoryID     uint       `json:"category_id" gorm:"not null;index"`
+	SubcategoryID  uint       `json:"subcategory_id" gorm:"not null;index"`
+	Variants       []Medicine `json:"variants,omitempty"`
+}
+
+type ProductCreateRequest struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Manufacturer   string `json:"manufacturer"`
+	ManufacturerID *uint  `json:"manufacturer_id"`
+	CategoryID     uint   `json:"category_id"`
+	SubcategoryID  uint   `json:"subcategory_id"`
+}
+
+type ProductUpdateRequest struct {
+	Name           *string `json:"name"`
+	Description    *string `json:"description"`
+	Manufacturer   *string `json:"manufacturer"`
+	ManufacturerID *uint   `json:"manufacturer_id"`
+	CategoryID     *uint   `json:"category_id"`
+	SubcategoryID  *uint   `json:"subcategory_id"`
+}
+
+type VariantCreateRequest struct {
+	SKU                  string  `json:"sku"`
+	Name                 string  `json:"name"`
+	Strength             string  `json:"strength"`
+	DosageForm           string  `json:"dosage_form"`
+	PackSize             int     `json:"pack_size"`
+	Price                float64 `json:"price"`
+	StockQuantity        int     `json:"stock_quantity"`
+	PrescriptionRequired bool    `json:"prescription_required"`
+	Actor                string  `json:"actor"`
+}
+
+type AttachVariantsRequest struct {
+	MedicineIDs []uint `json:"medicine_ids"`
+}
+
+type ProductListResponse struct {
+	Items         []Product `json:"items"`
+	Total         int64     `json:"total"`
+	NextPageToken string    `json:"next_page_token,omitempty"`
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderSent              PurchaseOrderStatus = "sent"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+)
+
+type Supplier struct {
+	gorm.Model
+	Name    string `json:"name" gorm:"not null"`
+	INN     string `json:"inn"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Address string `json:"address"`
+}
+
+type PurchaseOrder struct {
+	gorm.Model
+	SupplierID uint                `json:"supplier_id" gorm:"not null;index"`
+	Supplier   *Supplier           `json:"supplier,omitempty"`
+	BranchID   *uint               `json:"branch_id" gorm:"index"`
+	Status     PurchaseOrderStatus `json:"status" gorm:"not null;default:draft"`
+	Comment    string              `json:"comment"`
+	SentAt     *time.Time          `json:"sent_at"`
+	ReceivedAt *time.Time          `json:"received_at"`
+	Lines      []PurchaseOrderLine `json:"lines"`
+}
+
+type PurchaseOrderLine struct {
+	gorm.Model
+	PurchaseOrderID  uint    `json:"purchase_order_id" gorm:"not null;index"`
+	MedicineID       uint    `json:"medicine_id" gorm:"not null;index"`
+	MedicineName     string  `json:"medicine_name"`
+	Quantity         int     `json:"quantity"`
+	ReceivedQuantity int     `json:"received_quantity"`
+	PurchasePrice    float64 `json:"purchase_price"`
+}
+
+type GoodsReceipt struct {
+	gorm.Model
+	PurchaseOrderID uint               `json:"purchase_order_id" gorm:"not null;index"`
+	Actor           string             `json:"actor"`
+	Items           []GoodsReceiptItem `json:"items"`
+}
+
+type GoodsReceiptItem struct {
+	gorm.Model
+	GoodsReceiptID      uint       `json:"goods_receipt_id" gorm:"not null;index"`
+	PurchaseOrderLineID uint       `json:"purchase_order_line_id" gorm:"not null;index"`
+	MedicineID          uint       `json:"medicine_id" gorm:"not null;index"`
+	Quantity            int        `json:"quantity"`
+	PurchasePrice       float64    `json:"purchase_price"`
+	BatchID             *uint      `json:"batch_id"`
+	LotNumber           string     `json:"lot_number"`
+	ExpiryDate          *time.Time `json:"expiry_date"`
+}
+
+type SupplierCreateRequest struct {
+	Name    string `json:"name"`
+	INN     string `json:"inn"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Address string `json:"address"`
+}
+
+type SupplierUpdateRequest struct {
+	Name    *string `json:"name"`
+	INN     *string `json:"inn"`
+	Phone   *string `json:"phone"`
+	Email   *string `json:"email"`
+	Address *string `json:"address"`
+}
+
+type PurchaseOrderLineRequest struct {
+	MedicineID    uint    `json:"medicine_id"`
+	Quantity      int     `json:"quantity"`
+	PurchasePrice float64 `json:"purchase_price"`
+}
+
+type PurchaseOrderCreateRequest struct {
+	SupplierID uint                       `json:"supplier_id"`
+	BranchID   *uint                      `json:"branch_id"`
+	Comment    string                     `json:"comment"`
+	Lines      []PurchaseOrderLineRequest `json:"lines"`
+}
+
+type PurchaseOrderStatusRequest struct {
+	Status PurchaseOrderStatus `json:"status"`
+}
+
+type GoodsReceiptItemRequest struct {
+	LineID     uint       `json:"line_id"`
+	Quantity   int        `json:"quantity"`
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+}
+
+type GoodsReceiptRequest struct {
+	Actor string                    `json:"actor"`
+	Items []GoodsReceiptItemRequest `json:"items"`
+}
+
+type MarginReportRow struct {
+	MedicineID   uint    `json:"medicine_id"`
+	MedicineName string  `json:"medicine_name"`
+	SoldQuantity int64   `json:"sold_quantity"`
+	Revenue      float64 `json:"revenue"`
+	AverageCost  float64 `json:"average_cost"`
+	Cost         float64 `json:"cost"`
+	Margin       float64 `json:"margin"`
+	MarginPct    float64 `json:"margin_pct"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type RecommendationSource string
+
+const (
+	RecommendationBoughtTogether        RecommendationSource = "bought_together"
+	RecommendationSubcategoryBestseller RecommendationSource = "subcategory_bestseller"
+)
+
+type MedicineCoPurchase struct {
+	MedicineID uint      `json:"medicine_id" gorm:"primaryKey;autoIncrement:false"`
+	RelatedID  uint      `json:"related_id" gorm:"primaryKey;autoIncrement:false"`
+	OrderCount int64     `json:"order_count"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type MedicineRecommendation struct {
+	Medicine Medicine             `json:"medicine"`
+	Source   RecommendationSource `json:"source"`
+	Score    int64                `json:"score"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type LowStockAlertState struct {
+	MedicineID uint      `json:"medicine_id" gorm:"primaryKey;autoIncrement:false"`
+	AlertedAt  time.Time `json:"alerted_at"`
+}
+
+type LowStockAlert struct {
+	MedicineID    uint   `json:"medicine_id"`
+	Name          string `json:"name"`
+	StockQuantity int    `json:"stock_quantity"`
+	ReorderPoint  int    `json:"reorder_point"`
+	TargetStock   int    `json:"target_stock"`
+}
+
+type ReorderSuggestion struct {
+	MedicineID        uint     `json:"medicine_id"`
+	Name              string   `json:"name"`
+	StockQuantity     int      `json:"stock_quantity"`
+	ReorderPoint      int      `json:"reorder_point"`
+	TargetStock       int      `json:"target_stock"`
+	SoldInPeriod      int64    `json:"sold_in_period"`
+	DailyVelocity     float64  `json:"daily_velocity"`
+	DaysOfStockLeft   *float64 `json:"days_of_stock_left"`
+	SuggestedQuantity int      `json:"suggested_quantity"`
+}
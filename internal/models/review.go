@@ -1,15 +1,34 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
 type Review struct {
 	gorm.Model
-	UserID     uint   `json:"user_id"`
-	MedicineID uint   `json:"medicine_id"`
-	Rating     uint   `json:"rating"`
-	Text       string `json:"text"`
+	UserID           uint         `json:"user_id"`
+	MedicineID       uint         `json:"medicine_id"`
+	Rating           uint         `json:"rating"`
+	Text             string       `json:"text"`
+	VerifiedPurchase bool         `json:"verified_purchase" gorm:"not null;default:false"`
+	Status           ReviewStatus `json:"status" gorm:"size:16;not null;default:approved;index"`
+	Flagged          bool         `json:"flagged" gorm:"not null;default:false"`
+	FlagReason       string       `json:"flag_reason,omitempty"`
+	RejectionReason  string       `json:"rejection_reason,omitempty"`
+	ModeratedBy      string       `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time   `json:"moderated_at,omitempty"`
+	HelpfulCount     int64        `json:"helpful_count" gorm:"not null;default:0"`
+	NotHelpfulCount  int64        `json:"not_helpful_count" gorm:"not null;default:0"`
 }
 
 type ReviewForPost struct {
@@ -22,4 +41,30 @@ type ReviewForPost struct {
 type ReviewForUpdate struct {
     Rating *uint   `json:"rating" binding:"omitempty,min=1,max=5"`
     Text   *string `json:"text" binding:"omitempty"`
-}
\ No newline at end of file
+}
+
+type ReviewModerationRequest struct {
+	Reason string `json:"reason"`
+	Actor  string `json:"actor"`
+}
+
+type ReviewVote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ReviewID  uint      `json:"review_id" gorm:"not null;uniqueIndex:idx_review_votes_review_user"`
+	UserID    uint      `json:"user_id" gorm:"not null;index;uniqueIndex:idx_review_votes_review_user"`
+	Helpful   bool      `json:"helpful"`
+}
+
+type ReviewVoteRequest struct {
+	UserID  uint  `json:"user_id" binding:"required"`
+	Helpful *bool `json:"helpful" binding:"required"`
+}
+
+type ReviewListResponse struct {
+	Items              []Review       `json:"items"`
+	Total              int64          `json:"total"`
+	NextPageToken      string         `json:"next_page_token,omitempty"`
+	RatingDistribution map[uint]int64 `json:"rating_distribution,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type StockMovementType string
+
+const (
+	MovementReceipt     StockMovementType = "receipt"
+	MovementSale        StockMovementType = "sale"
+	MovementReturn      StockMovementType = "return"
+	MovementWriteOff    StockMovementType = "write_off"
+	MovementAdjustment  StockMovementType = "adjustment"
+	MovementReservation StockMovementType = "reservation"
+)
+
+type StockMovement struct {
+	ID           uint              `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time         `json:"created_at" gorm:"index"`
+	MedicineID   uint              `json:"medicine_id" gorm:"not null;index"`
+	BatchID      *uint             `json:"batch_id" gorm:"index"`
+	Type         StockMovementType `json:"type" gorm:"not null"`
+	Quantity     int               `json:"quantity"`
+	BalanceAfter int               `json:"balance_after"`
+	Actor        string            `json:"actor"`
+	Reason       string            `json:"reason"`
+}
+
+type StockAdjustmentRequest struct {
+	Type     StockMovementType `json:"type"`
+	Quantity int               `json:"quantity"`
+	BatchID  *uint             `json:"batch_id"`
+	Actor    string            `json:"actor"`
+	Reason   string            `json:"reason"`
+}
+
+type StockHistoryResponse struct {
+	Items []StockMovement `json:"items"`
+	Total int64           `json:"total"`
+}
+
+type StockReconciliation struct {
+	MedicineID   uint   `json:"medicine_id"`
+	MedicineName string `json:"medicine_name"`
+	LedgerTotal  int    `json:"ledger_total"`
+	OnHand       int    `json:"on_hand"`
+	Difference   int    `json:"difference"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type SubscriptionType string
+
+const (
+	SubscriptionBackInStock SubscriptionType = "back_in_stock"
+	SubscriptionPriceDrop   SubscriptionType = "price_drop"
+)
+
+type MedicineSubscription struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	UserID      uint             `json:"user_id" gorm:"not null;uniqueIndex:idx_subscription_user_medicine_type"`
+	MedicineID  uint             `json:"medicine_id" gorm:"not null;index;uniqueIndex:idx_subscription_user_medicine_type"`
+	Type        SubscriptionType `json:"type" gorm:"not null;uniqueIndex:idx_subscription_user_medicine_type"`
+	TargetPrice *float64         `json:"target_price,omitempty"`
+	ClaimedAt   *time.Time       `json:"-" gorm:"index"`
+}
+
+type SubscriptionCreateRequest struct {
+	UserID      uint             `json:"user_id"`
+	Type        SubscriptionType `json:"type"`
+	TargetPrice *float64         `json:"target_price"`
+}
+
+type DueSubscription struct {
+	MedicineSubscription
+	MedicineName  string  `json:"medicine_name"`
+	Price         float64 `json:"price"`
+	StockQuantity int     `json:"stock_quantity"`
+	Email         string  `json:"email"`
+}
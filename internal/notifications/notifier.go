@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Notification struct {
+	Type      string         `json:"type"`
+	Recipient string         `json:"recipient,omitempty"`
+	Subject   string         `json:"subject"`
+	Message   string         `json:"message"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+type logNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.logger.InfoContext(ctx, "notifications: "+notification.Subject,
+		slog.String("type", notification.Type),
+		slog.String("recipient", notification.Recipient),
+		slog.String("message", notification.Message),
+		slog.Any("data", notification.Data),
+	)
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) Notifier {
+	return &webhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook вернул статус %d", resp.StatusCode)
+	}
+	return nil
+}
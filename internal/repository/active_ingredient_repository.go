@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+)
+
+type AnalogMatch struct {
+	MedicineID       uint
+	SharedCount      int
+	IngredientsCount int
+}
+
+type ActiveIngredientRepository interface {
+	Create(ingredient *models.ActiveIngredient) error
+
+	GetAll() ([]models.ActiveIngredient, error)
+
+	GetByIDs(ids []uint) ([]models.ActiveIngredient, error)
+
+	ListByMedicineID(medicineID uint) ([]models.MedicineIngredient, error)
+
+	ListByMedicineIDs(medicineIDs []uint) ([]models.MedicineIngredient, error)
+
+	ReplaceForMedicine(medicineID uint, ingredients []models.MedicineIngredient) error
+
+	FindAnalogs(medicineID uint) ([]AnalogMatch, error)
+}
+
+type gormActiveIngredientRepository struct {
+	db *gorm.DB
+}
+
+func NewActiveIngredientRepository(db *gorm.DB) ActiveIngredientRepository {
+	return &gormActiveIngredientRepository{db: db}
+}
+
+func (r *gormActiveIngredientRepository) Create(ingredient *models.ActiveIngredient) error {
+	if ingredient == nil {
+		return nil
+	}
+	return r.db.Create(ingredient).Error
+}
+
+func (r *gormActiveIngredientRepository) GetAll() ([]models.ActiveIngredient, error) {
+	var ingredients []models.ActiveIngredient
+
+	if err := r.db.Order("name").Find(&ingredients).Error; err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func (r *gormActiveIngredientRepository) GetByIDs(ids []uint) ([]models.ActiveIngredient, error) {
+	var ingredients []models.ActiveIngredient
+
+	if err := r.db.Where("id IN ?", ids).Find(&ingredients).Error; err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func (r *gormActiveIngredientRepository) ListByMedicineID(medicineID uint) ([]models.MedicineIngredient, error) {
+	var ingredients []models.MedicineIngredient
+
+	err := r.db.Preload("ActiveIngredient").
+		Where("medicine_id = ?", medicineID).
+		Find(&ingredients).Error
+	if err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func (r *gormActiveIngredientRepository) ListByMedicineIDs(medicineIDs []uint) ([]models.MedicineIngredient, error) {
+	var ingredients []models.MedicineIngredient
+
+	if len(medicineIDs) == 0 {
+		return ingredients, nil
+	}
+
+	err := r.db.Preload("ActiveIngredient").
+		Where("medicine_id IN ?", medicineIDs).
+		Find(&ingredients).Error
+	if err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func (r *gormActiveIngredientRepository) ReplaceForMedicine(medicineID uint, ingredients []models.MedicineIngredient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("medicine_id = ?", medicineID).Delete(&models.MedicineIngredient{}).Error; err != nil {
+			return err
+		}
+
+		if len(ingredients) == 0 {
+			return nil
+		}
+
+		return tx.Omit("ActiveIngredient").Create(&ingredients).Error
+	})
+}
+
+func (r *gormActiveIngredientRepository) FindAnalogs(medicineID uint) ([]AnalogMatch, error) {
+	var matches []AnalogMatch
+
+	err := r.db.Raw(`
+		SELECT mi.medicine_id,
+			count(*) AS shared_count,
+			(SELECT count(*) FROM medicine_ingredients all_mi WHERE all_mi.medicine_id = mi.medicine_id) AS ingredients_count
+		FROM medicine_ingredients mi
+		JOIN medicines m ON m.id = mi.medicine_id AND m.deleted_at IS NULL
+		WHERE mi.medicine_id <> @id
+			AND mi.active_ingredient_id IN (
+				SELECT active_ingredient_id FROM medicine_ingredients WHERE medicine_id = @id
+			)
+		GROUP BY mi.medicine_id`,
+		sql.Named("id", medicineID),
+	).Scan(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
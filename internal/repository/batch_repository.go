@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("недостаточно товара на складе")
+
+type BatchRepository interface {
+	Create(batch *models.Batch, actor string) error
+
+	ListByMedicineID(medicineID uint) ([]models.Batch, error)
+
+	CountByMedicineID(medicineID uint) (int64, error)
+
+	SyncMedicineStock(medicineID uint) error
+
+	ListExpiring(now, until time.Time) ([]models.Batch, error)
+
+	BlockExpired(now time.Time) ([]uint, error)
+}
+
+type gormBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewBatchRepository(db *gorm.DB) BatchRepository {
+	return &gormBatchRepository{db: db}
+}
+
+func (r *gormBatchRepository) Create(batch *models.Batch, actor string) error {
+	if batch == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if err := syncMedicineStock(tx, batch.MedicineID, time.Now()); err != nil {
+			return err
+		}
+
+		_, err := recordMovement(tx, batch.MedicineID, &batch.ID, batch.Quantity, movementInfo{
+			Type:   models.MovementReceipt,
+			Actor:  actor,
+			Reason: "поступление партии " + batch.LotNumber,
+		})
+		return err
+	})
+}
+
+func (r *gormBatchRepository) ListByMedicineID(medicineID uint) ([]models.Batch, error) {
+	var batches []models.Batch
+
+	if err := r.db.Where("medicine_id = ?", medicineID).Order("expiry_date, id").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *gormBatchRepository) CountByMedicineID(medicineID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.Batch{}).Where("medicine_id = ?", medicineID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *gormBatchRepository) SyncMedicineStock(medicineID uint) error {
+	return syncMedicineStock(r.db, medicineID, time.Now())
+}
+
+func (r *gormBatchRepository) ListExpiring(now, until time.Time) ([]models.Batch, error) {
+	var batches []models.Batch
+
+	err := r.db.Preload("Medicine").
+		Where("quantity > 0 AND expiry_date > ? AND expiry_date <= ?", now, until).
+		Order("expiry_date, id").
+		Find(&batches).Error
+	if err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *gormBatchRepository) BlockExpired(now time.Time) ([]uint, error) {
+	var medicineIDs []uint
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var batches []models.Batch
+		err := tx.Model(&batches).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "medicine_id"}}}).
+			Where("blocked_at IS NULL AND expiry_date <= ?", now).
+			Update("blocked_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[uint]bool)
+		for _, b := range batches {
+			if seen[b.MedicineID] {
+				continue
+			}
+			seen[b.MedicineID] = true
+			medicineIDs = append(medicineIDs, b.MedicineID)
+
+			if err := syncMedicineStock(tx, b.MedicineID, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return medicineIDs, nil
+}
+
+type batchAllocation struct {
+	BatchID   *uint
+	LotNumber string
+	Quantity  int
+}
+
+func allocateBatches(tx *gorm.DB, medicineID uint, branchID *uint, quantity int, now time.Time, info movementInfo) ([]batchAllocation, error) {
+	var total int64
+	if err := tx.Model(&models.Batch{}).Where("medicine_id = ?", medicineID).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	if total == 0 {
+		if err := adjustMedicineStock(tx, medicineID, -quantity); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrInsufficientStock
+			}
+			return nil, err
+		}
+		if _, err := recordMovement(tx, medicineID, nil, -quantity, info); err != nil {
+			return nil, err
+		}
+		return []batchAllocation{{Quantity: quantity}}, nil
+	}
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("medicine_id = ? AND quantity > 0 AND blocked_at IS NULL AND expiry_date > ?", medicineID, now)
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	}
+
+	var batches []models.Batch
+	err := query.Order("expiry_date, id").Find(&batches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := planBatchAllocation(batches, quantity, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, allocation := range allocations {
+		err := tx.Model(&models.Batch{}).
+			Where("id = ?", *allocation.BatchID).
+			Update("quantity", gorm.Expr("quantity - ?", allocation.Quantity)).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := recordMovement(tx, medicineID, allocation.BatchID, -allocation.Quantity, info); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := syncMedicineStock(tx, medicineID, now); err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+func planBatchAllocation(batches []models.Batch, quantity int, now time.Time) ([]batchAllocation, error) {
+	available := make([]models.Batch, 0, len(batches))
+	for _, batch := range batches {
+		if batch.Quantity > 0 && batch.BlockedAt == nil && batch.ExpiryDate.After(now) {
+			available = append(available, batch)
+		}
+	}
+
+	sort.SliceStable(available, func(i, j int) bool {
+		if !available[i].ExpiryDate.Equal(available[j].ExpiryDate) {
+			return available[i].ExpiryDate.Before(available[j].ExpiryDate)
+		}
+		return available[i].ID < available[j].ID
+	})
+
+	var allocations []batchAllocation
+	remaining := quantity
+	for _, batch := range available {
+		if remaining == 0 {
+			break
+		}
+
+		take := min(batch.Quantity, remaining)
+		batchID := batch.ID
+		allocations = append(allocations, batchAllocation{
+			BatchID:   &batchID,
+			LotNumber: batch.LotNumber,
+			Quantity:  take,
+		})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+
+	return allocations, nil
+}
+
+func syncMedicineStock(tx *gorm.DB, medicineID uint, now time.Time) error {
+	return tx.Exec(`
+		UPDATE medicines SET stock_quantity = b.total, in_stock = b.total > 0
+		FROM (
+			SELECT coalesce(sum(quantity), 0) AS total
+			FROM batches
+			WHERE medicine_id = @id AND deleted_at IS NULL AND blocked_at IS NULL AND expiry_date > @now
+		) AS b
+		WHERE medicines.id = @id
+			AND EXISTS (SELECT 1 FROM batches WHERE medicine_id = @id AND deleted_at IS NULL)`,
+		sql.Named("id", medicineID),
+		sql.Named("now", now),
+	).Error
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestPlanBatchAllocation(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	blocked := now.Add(-day)
+
+	batch := func(id uint, lot string, expiresIn time.Duration, quantity int) models.Batch {
+		return models.Batch{
+			Model:      gorm.Model{ID: id},
+			LotNumber:  lot,
+			ExpiryDate: now.Add(expiresIn),
+			Quantity:   quantity,
+		}
+	}
+
+	type want struct {
+		lot      string
+		quantity int
+	}
+
+	tests := []struct {
+		name     string
+		batches  []models.Batch
+		quantity int
+		want     []want
+		err      error
+	}{
+		{
+			name:     "single batch covers quantity",
+			batches:  []models.Batch{batch(1, "A", 30*day, 10)},
+			quantity: 4,
+			want:     []want{{"A", 4}},
+		},
+		{
+			name: "earliest expiry first regardless of input order",
+			batches: []models.Batch{
+				batch(1, "late", 90*day, 10),
+				batch(2, "early", 10*day, 10),
+				batch(3, "middle", 40*day, 10),
+			},
+			quantity: 5,
+			want:     []want{{"early", 5}},
+		},
+		{
+			name: "spills over into next batch",
+			batches: []models.Batch{
+				batch(1, "late", 90*day, 10),
+				batch(2, "early", 10*day, 3),
+			},
+			quantity: 7,
+			want:     []want{{"early", 3}, {"late", 4}},
+		},
+		{
+			name: "same expiry ordered by id",
+			batches: []models.Batch{
+				batch(5, "second", 20*day, 2),
+				batch(4, "first", 20*day, 2),
+			},
+			quantity: 3,
+			want:     []want{{"first", 2}, {"second", 1}},
+		},
+		{
+			name: "skips expired, blocked and empty batches",
+			batches: func() []models.Batch {
+				blockedBatch := batch(2, "blocked", 5*day, 10)
+				blockedBatch.BlockedAt = &blocked
+				return []models.Batch{
+					batch(1, "expired", -day, 10),
+					blockedBatch,
+					batch(3, "empty", 6*day, 0),
+					batch(4, "expires-now", 0, 10),
+					batch(5, "ok", 7*day, 10),
+				}
+			}(),
+			quantity: 6,
+			want:     []want{{"ok", 6}},
+		},
+		{
+			name: "exact total consumes every batch",
+			batches: []models.Batch{
+				batch(1, "A", 10*day, 2),
+				batch(2, "B", 20*day, 3),
+			},
+			quantity: 5,
+			want:     []want{{"A", 2}, {"B", 3}},
+		},
+		{
+			name: "insufficient stock",
+			batches: []models.Batch{
+				batch(1, "A", 10*day, 2),
+				batch(2, "expired", -day, 50),
+			},
+			quantity: 3,
+			err:      ErrInsufficientStock,
+		},
+		{
+			name:     "no batches",
+			quantity: 1,
+			err:      ErrInsufficientStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planBatchAllocation(tt.batches, tt.quantity, now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d allocations, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				if got[i].LotNumber != w.lot || got[i].Quantity != w.quantity {
+					t.Errorf("allocation %d = %s x%d, want %s x%d", i, got[i].LotNumber, got[i].Quantity, w.lot, w.quantity)
+				}
+				if got[i].BatchID == nil {
+					t.Errorf("allocation %d has no batch id", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+)
+
+const haversineKmSQL = "6371 * 2 * asin(sqrt(" +
+	"power(sin(radians(branches.latitude - ?) / 2), 2) + " +
+	"cos(radians(?)) * cos(radians(branches.latitude)) * power(sin(radians(branches.longitude - ?) / 2), 2)))"
+
+const availableBatchCondition = "batches.deleted_at IS NULL AND batches.blocked_at IS NULL " +
+	"AND batches.expiry_date > now() AND batches.quantity > 0"
+
+type StockLocation struct {
+	BranchID  *uint
+	Latitude  *float64
+	Longitude *float64
+	RadiusKm  float64
+}
+
+func (l StockLocation) HasCoordinates() bool {
+	return l.Latitude != nil && l.Longitude != nil
+}
+
+type BranchRepository interface {
+	Create(branch *models.Branch) error
+
+	GetByID(id uint) (*models.Branch, error)
+
+	GetAll() ([]models.Branch, error)
+
+	Update(branch *models.Branch) error
+
+	Delete(id uint) error
+
+	CountBatches(id uint) (int64, error)
+
+	Availability(medicineID uint, latitude, longitude *float64) ([]models.BranchAvailability, error)
+}
+
+type gormBranchRepository struct {
+	db *gorm.DB
+}
+
+func NewBranchRepository(db *gorm.DB) BranchRepository {
+	return &gormBranchRepository{db: db}
+}
+
+func (r *gormBranchRepository) Create(branch *models.Branch) error {
+	if branch == nil {
+		return nil
+	}
+
+	return r.db.Create(branch).Error
+}
+
+func (r *gormBranchRepository) GetByID(id uint) (*models.Branch, error) {
+	var branch models.Branch
+
+	if err := r.db.First(&branch, id).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *gormBranchRepository) GetAll() ([]models.Branch, error) {
+	var branches []models.Branch
+
+	if err := r.db.Order("name, id").Find(&branches).Error; err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+func (r *gormBranchRepository) Update(branch *models.Branch) error {
+	if branch == nil {
+		return nil
+	}
+
+	return r.db.Save(branch).Error
+}
+
+func (r *gormBranchRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Branch{}, id).Error
+}
+
+func (r *gormBranchRepository) CountBatches(id uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.Batch{}).Where("branch_id = ? AND quantity > 0", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *gormBranchRepository) Availability(medicineID uint, latitude, longitude *float64) ([]models.BranchAvailability, error) {
+	var rows []models.BranchAvailability
+
+	query := r.db.Table("branches").
+		Joins("JOIN batches ON batches.branch_id = branches.id AND "+availableBatchCondition).
+		Where("branches.deleted_at IS NULL AND batches.medicine_id = ?", medicineID).
+		Group("branches.id").
+		Having("sum(batches.quantity) > 0")
+
+	columns := "branches.id AS branch_id, branches.name, branches.address, branches.latitude, branches.longitude, " +
+		"sum(batches.quantity) AS quantity"
+
+	if latitude != nil && longitude != nil {
+		query = query.
+			Select(columns+", "+haversineKmSQL+" AS distance_km", *latitude, *latitude, *longitude).
+			Order("distance_km, branches.id")
+	} else {
+		query = query.Select(columns).Order("quantity DESC, branches.id")
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func localStockSQL(location StockLocation) (string, []any) {
+	expr := "(SELECT coalesce(sum(batches.quantity), 0) FROM batches " +
+		"JOIN branches ON branches.id = batches.branch_id AND branches.deleted_at IS NULL " +
+		"WHERE batches.medicine_id = medicines.id AND " + availableBatchCondition
+
+	if location.BranchID != nil {
+		return expr + " AND batches.branch_id = ?)", []any{*location.BranchID}
+	}
+
+	return expr + " AND " + haversineKmSQL + " <= ?)",
+		[]any{*location.Latitude, *location.Latitude, *location.Longitude, location.RadiusKm}
+}
@@ -19,6 +19,28 @@ type CategoryRepository interface {
 	GetCategoryByID(id uint) (*models.Category, error)
 
 	GetSubcategoryByID(id uint) (*models.Subcategory, error)
+
+	GetAllSubcategories() ([]models.Subcategory, error)
+
+	UpdateCategory(category *models.Category) error
+
+	DeleteCategory(id uint, reassignTo *uint) error
+
+	UpdateSubcategory(subcategory *models.Subcategory) error
+
+	DeleteSubcategory(id uint, reassignTo *models.Subcategory) error
+
+	CountMedicinesByCategoryID(categoryID uint) (int64, error)
+
+	CountMedicinesBySubcategoryID(subcategoryID uint) (int64, error)
+
+	CountMedicinesGrouped() ([]CategoryMedicineCount, error)
+}
+
+type CategoryMedicineCount struct {
+	CategoryID    uint
+	SubcategoryID uint
+	Count         int64
 }
 
 type gormCategoryRepository struct {
@@ -117,3 +139,159 @@ func (r *gormCategoryRepository) GetSubcategoryByID(id uint) (*models.Subcategor
 	r.logger.Info("category_repository.GetSubcategoryByID: subcategory fetched successfully", slog.Uint64("id", uint64(id)), slog.String("name", subcategory.Name))
 	return &subcategory, nil
 }
+
+func (r *gormCategoryRepository) GetAllSubcategories() ([]models.Subcategory, error) {
+	var subcategories []models.Subcategory
+
+	r.logger.Info("category_repository.GetAllSubcategories: fetching all subcategories")
+	if err := r.db.Order("id").Find(&subcategories).Error; err != nil {
+		r.logger.Error("category_repository.GetAllSubcategories: failed to fetch subcategories", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	r.logger.Info("category_repository.GetAllSubcategories: subcategories fetched successfully", slog.Int("count", len(subcategories)))
+	return subcategories, nil
+}
+
+func (r *gormCategoryRepository) UpdateCategory(category *models.Category) error {
+	if category == nil {
+		r.logger.Error("category_repository.UpdateCategory: category is nil")
+		return gorm.ErrInvalidData
+	}
+
+	r.logger.Info("category_repository.UpdateCategory: updating category", slog.Uint64("id", uint64(category.ID)))
+	if err := r.db.Save(category).Error; err != nil {
+		r.logger.Error("category_repository.UpdateCategory: failed to update category", slog.String("error", err.Error()), slog.Uint64("id", uint64(category.ID)))
+		return err
+	}
+
+	r.logger.Info("category_repository.UpdateCategory: category updated successfully", slog.Uint64("id", uint64(category.ID)))
+	return nil
+}
+
+func (r *gormCategoryRepository) DeleteCategory(id uint, reassignTo *uint) error {
+	r.logger.Info("category_repository.DeleteCategory: deleting category", slog.Uint64("id", uint64(id)))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if reassignTo != nil {
+			if err := tx.Model(&models.Medicine{}).Where("category_id = ?", id).Update("category_id", *reassignTo).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Product{}).Where("category_id = ?", id).Update("category_id", *reassignTo).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Subcategory{}).Where("category_id = ?", id).Update("category_id", *reassignTo).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Where("category_id = ?", id).Delete(&models.Subcategory{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Category{}, id).Error
+	})
+	if err != nil {
+		r.logger.Error("category_repository.DeleteCategory: failed to delete category", slog.String("error", err.Error()), slog.Uint64("id", uint64(id)))
+		return err
+	}
+
+	r.logger.Info("category_repository.DeleteCategory: category deleted successfully", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (r *gormCategoryRepository) UpdateSubcategory(subcategory *models.Subcategory) error {
+	if subcategory == nil {
+		r.logger.Error("category_repository.UpdateSubcategory: subcategory is nil")
+		return gorm.ErrInvalidData
+	}
+
+	r.logger.Info("category_repository.UpdateSubcategory: updating subcategory", slog.Uint64("id", uint64(subcategory.ID)))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(subcategory).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.Medicine{}).
+			Where("subcategory_id = ? AND category_id <> ?", subcategory.ID, subcategory.CategoryID).
+			Update("category_id", subcategory.CategoryID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Product{}).
+			Where("subcategory_id = ? AND category_id <> ?", subcategory.ID, subcategory.CategoryID).
+			Update("category_id", subcategory.CategoryID).Error
+	})
+	if err != nil {
+		r.logger.Error("category_repository.UpdateSubcategory: failed to update subcategory", slog.String("error", err.Error()), slog.Uint64("id", uint64(subcategory.ID)))
+		return err
+	}
+
+	r.logger.Info("category_repository.UpdateSubcategory: subcategory updated successfully", slog.Uint64("id", uint64(subcategory.ID)))
+	return nil
+}
+
+func (r *gormCategoryRepository) DeleteSubcategory(id uint, reassignTo *models.Subcategory) error {
+	r.logger.Info("category_repository.DeleteSubcategory: deleting subcategory", slog.Uint64("id", uint64(id)))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if reassignTo != nil {
+			moved := map[string]any{
+				"category_id":    reassignTo.CategoryID,
+				"subcategory_id": reassignTo.ID,
+			}
+			if err := tx.Model(&models.Medicine{}).Where("subcategory_id = ?", id).Updates(moved).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Product{}).Where("subcategory_id = ?", id).Updates(moved).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&models.Subcategory{}, id).Error
+	})
+	if err != nil {
+		r.logger.Error("category_repository.DeleteSubcategory: failed to delete subcategory", slog.String("error", err.Error()), slog.Uint64("id", uint64(id)))
+		return err
+	}
+
+	r.logger.Info("category_repository.DeleteSubcategory: subcategory deleted successfully", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (r *gormCategoryRepository) CountMedicinesByCategoryID(categoryID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.Medicine{}).Where("category_id = ?", categoryID).Count(&count).Error; err != nil {
+		r.logger.Error("category_repository.CountMedicinesByCategoryID: failed to count medicines", slog.String("error", err.Error()), slog.Uint64("category_id", uint64(categoryID)))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormCategoryRepository) CountMedicinesBySubcategoryID(subcategoryID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.Medicine{}).Where("subcategory_id = ?", subcategoryID).Count(&count).Error; err != nil {
+		r.logger.Error("category_repository.CountMedicinesBySubcategoryID: failed to count medicines", slog.String("error", err.Error()), slog.Uint64("subcategory_id", uint64(subcategoryID)))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormCategoryRepository) CountMedicinesGrouped() ([]CategoryMedicineCount, error) {
+	var counts []CategoryMedicineCount
+
+	err := r.db.Model(&models.Medicine{}).
+		Select("category_id, subcategory_id, count(*) AS count").
+		Group("category_id, subcategory_id").
+		Scan(&counts).Error
+	if err != nil {
+		r.logger.Error("category_repository.CountMedicinesGrouped: failed to count medicines", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+
+	return db, mock
+}
+
+func TestDeleteCategoryReassignsProducts(t *testing.T) {
+	db, mock := newMockDB(t)
+	target := uint(7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "medicines" SET "category_id"=\$1,"updated_at"=\$2 WHERE category_id = \$3`).
+		WithArgs(target, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE "products" SET "category_id"=\$1,"updated_at"=\$2 WHERE category_id = \$3`).
+		WithArgs(target, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "subcategories" SET "category_id"=\$1,"updated_at"=\$2 WHERE category_id = \$3`).
+		WithArgs(target, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "categories" SET "deleted_at"=\$1 WHERE "categories"."id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := NewCategoryRepository(db).DeleteCategory(3, &target); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteSubcategoryReassignsProducts(t *testing.T) {
+	db, mock := newMockDB(t)
+	target := &models.Subcategory{CategoryID: 7}
+	target.ID = 12
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "medicines" SET "category_id"=\$1,"subcategory_id"=\$2,"updated_at"=\$3 WHERE subcategory_id = \$4`).
+		WithArgs(uint(7), uint(12), sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE "products" SET "category_id"=\$1,"subcategory_id"=\$2,"updated_at"=\$3 WHERE subcategory_id = \$4`).
+		WithArgs(uint(7), uint(12), sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "subcategories" SET "deleted_at"=\$1 WHERE "subcategories"."id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := NewCategoryRepository(db).DeleteSubcategory(5, target); err != nil {
+		t.Fatalf("DeleteSubcategory: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(job *models.ImportJob) error
+
+	GetByID(id uint) (*models.ImportJob, error)
+
+	Update(job *models.ImportJob) error
+
+	Finish(job *models.ImportJob, rowErrors []models.ImportRowError) error
+
+	FailUnfinished(message string, now time.Time) (int64, error)
+}
+
+type gormImportJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &gormImportJobRepository{db: db}
+}
+
+func (r *gormImportJobRepository) Create(job *models.ImportJob) error {
+	if job == nil {
+		return nil
+	}
+
+	return r.db.Omit("Errors").Create(job).Error
+}
+
+func (r *gormImportJobRepository) GetByID(id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+
+	err := r.db.Preload("Errors", func(db *gorm.DB) *gorm.DB { return db.Order("row") }).
+		First(&job, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *gormImportJobRepository) Update(job *models.ImportJob) error {
+	if job == nil {
+		return nil
+	}
+
+	return r.db.Omit("Errors").Save(job).Error
+}
+
+func (r *gormImportJobRepository) Finish(job *models.ImportJob, rowErrors []models.ImportRowError) error {
+	if job == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Errors").Save(job).Error; err != nil {
+			return err
+		}
+
+		if len(rowErrors) == 0 {
+			return nil
+		}
+
+		for i := range rowErrors {
+			rowErrors[i].ImportJobID = job.ID
+		}
+		return tx.CreateInBatches(rowErrors, 500).Error
+	})
+}
+
+func (r *gormImportJobRepository) FailUnfinished(message string, now time.Time) (int64, error) {
+	result := r.db.Model(&models.ImportJob{}).
+		Where("status IN ?", []models.ImportJobStatus{models.ImportJobPending, models.ImportJobRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ImportJobFailed,
+			"error":       message,
+			"finished_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
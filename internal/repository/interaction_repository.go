@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+)
+
+type InteractionRepository interface {
+	FindByIngredients(names []string) ([]models.DrugInteraction, error)
+}
+
+type fileInteractionRepository struct {
+	pairs map[[2]string]models.DrugInteraction
+}
+
+func NewFileInteractionRepository(path string) (InteractionRepository, error) {
+	repo := &fileInteractionRepository{pairs: make(map[[2]string]models.DrugInteraction)}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var interactions []models.DrugInteraction
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		interactions, err = readInteractionsJSON(file)
+	case ".csv":
+		interactions, err = readInteractionsCSV(file)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла взаимодействий: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, interaction := range interactions {
+		if interaction.IngredientA == "" || interaction.IngredientB == "" {
+			return nil, fmt.Errorf("взаимодействие #%d: не указаны действующие вещества", i+1)
+		}
+		if !interaction.Severity.Valid() {
+			return nil, fmt.Errorf("взаимодействие #%d: неизвестная степень тяжести %q", i+1, interaction.Severity)
+		}
+		repo.pairs[interactionKey(interaction.IngredientA, interaction.IngredientB)] = interaction
+	}
+
+	return repo, nil
+}
+
+func (r *fileInteractionRepository) FindByIngredients(names []string) ([]models.DrugInteraction, error) {
+	var result []models.DrugInteraction
+
+	for i := 0; i < len(names); i++ {
+		for j := i + 1; j < len(names); j++ {
+			if interaction, ok := r.pairs[interactionKey(names[i], names[j])]; ok {
+				result = append(result, interaction)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func interactionKey(a, b string) [2]string {
+	a = strings.ToLower(strings.TrimSpace(a))
+	b = strings.ToLower(strings.TrimSpace(b))
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+func readInteractionsJSON(r io.Reader) ([]models.DrugInteraction, error) {
+	var interactions []models.DrugInteraction
+	if err := json.NewDecoder(r).Decode(&interactions); err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+func readInteractionsCSV(r io.Reader) ([]models.DrugInteraction, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(header[0]), "ingredient_a") {
+		return nil, errors.New("ожидается заголовок ingredient_a,ingredient_b,severity,description")
+	}
+
+	var interactions []models.DrugInteraction
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		interactions = append(interactions, models.DrugInteraction{
+			IngredientA: strings.TrimSpace(record[0]),
+			IngredientB: strings.TrimSpace(record[1]),
+			Severity:    models.InteractionSeverity(strings.ToLower(strings.TrimSpace(record[2]))),
+			Description: strings.TrimSpace(record[3]),
+		})
+	}
+
+	return interactions, nil
+}
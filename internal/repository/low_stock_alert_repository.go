@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LowStockAlertRepository interface {
+	ListAlerted() ([]uint, error)
+
+	MarkAlerted(medicineID uint, at time.Time) error
+
+	ClearExcept(medicineIDs []uint) error
+}
+
+type gormLowStockAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewLowStockAlertRepository(db *gorm.DB) LowStockAlertRepository {
+	return &gormLowStockAlertRepository{db: db}
+}
+
+func (r *gormLowStockAlertRepository) ListAlerted() ([]uint, error) {
+	var ids []uint
+
+	if err := r.db.Model(&models.LowStockAlertState{}).Pluck("medicine_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *gormLowStockAlertRepository) MarkAlerted(medicineID uint, at time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "medicine_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"alerted_at"}),
+	}).Create(&models.LowStockAlertState{MedicineID: medicineID, AlertedAt: at}).Error
+}
+
+func (r *gormLowStockAlertRepository) ClearExcept(medicineIDs []uint) error {
+	query := r.db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	if len(medicineIDs) > 0 {
+		query = query.Where("medicine_id NOT IN ?", medicineIDs)
+	}
+	return query.Delete(&models.LowStockAlertState{}).Error
+}
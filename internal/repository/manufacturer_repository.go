@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ManufacturerRepository interface {
+	Create(manufacturer *models.Manufacturer) error
+
+	GetByID(id uint) (*models.Manufacturer, error)
+
+	GetAll(query string) ([]models.Manufacturer, error)
+
+	FindByName(name string) (*models.Manufacturer, error)
+
+	Update(manufacturer *models.Manufacturer, aliases []string) error
+
+	Delete(id uint) error
+
+	CountMedicines(id uint) (int64, error)
+
+	Merge(target *models.Manufacturer, sourceIDs []uint) error
+}
+
+type gormManufacturerRepository struct {
+	db *gorm.DB
+}
+
+func NewManufacturerRepository(db *gorm.DB) ManufacturerRepository {
+	return &gormManufacturerRepository{db: db}
+}
+
+func (r *gormManufacturerRepository) Create(manufacturer *models.Manufacturer) error {
+	if manufacturer == nil {
+		return nil
+	}
+
+	return r.db.Create(manufacturer).Error
+}
+
+func (r *gormManufacturerRepository) GetByID(id uint) (*models.Manufacturer, error) {
+	var manufacturer models.Manufacturer
+
+	if err := r.db.Preload("Aliases").First(&manufacturer, id).Error; err != nil {
+		return nil, err
+	}
+	return &manufacturer, nil
+}
+
+func (r *gormManufacturerRepository) GetAll(query string) ([]models.Manufacturer, error) {
+	var manufacturers []models.Manufacturer
+
+	db := r.db.Preload("Aliases").Order("name, id")
+	if query != "" {
+		pattern := "%" + query + "%"
+		db = db.Where("name ILIKE ? OR id IN (SELECT manufacturer_id FROM manufacturer_aliases WHERE name ILIKE ?)", pattern, pattern)
+	}
+
+	if err := db.Find(&manufacturers).Error; err != nil {
+		return nil, err
+	}
+	return manufacturers, nil
+}
+
+func (r *gormManufacturerRepository) FindByName(name string) (*models.Manufacturer, error) {
+	var manufacturer models.Manufacturer
+
+	err := r.db.Preload("Aliases").
+		Where("lower(name) = lower(?) OR id IN (SELECT manufacturer_id FROM manufacturer_aliases WHERE lower(name) = lower(?))", name, name).
+		Order("id").
+		First(&manufacturer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &manufacturer, nil
+}
+
+func (r *gormManufacturerRepository) Update(manufacturer *models.Manufacturer, aliases []string) error {
+	if manufacturer == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Aliases").Save(manufacturer).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []any{&models.Medicine{}, &models.Product{}} {
+			err := tx.Model(model).
+				Where("manufacturer_id = ?", manufacturer.ID).
+				Update("manufacturer", manufacturer.Name).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if aliases == nil {
+			return nil
+		}
+
+		if err := tx.Where("manufacturer_id = ?", manufacturer.ID).Delete(&models.ManufacturerAlias{}).Error; err != nil {
+			return err
+		}
+
+		manufacturer.Aliases = make([]models.ManufacturerAlias, 0, len(aliases))
+		for _, alias := range aliases {
+			manufacturer.Aliases = append(manufacturer.Aliases, models.ManufacturerAlias{
+				ManufacturerID: manufacturer.ID,
+				Name:           alias,
+			})
+		}
+
+		if len(manufacturer.Aliases) == 0 {
+			return nil
+		}
+		return tx.Create(&manufacturer.Aliases).Error
+	})
+}
+
+func (r *gormManufacturerRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("manufacturer_id = ?", id).Delete(&models.ManufacturerAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Manufacturer{}, id).Error
+	})
+}
+
+func (r *gormManufacturerRepository) CountMedicines(id uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.Medicine{}).Where("manufacturer_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *gormManufacturerRepository) Merge(target *models.Manufacturer, sourceIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var sources []models.Manufacturer
+		if err := tx.Where("id IN ?", sourceIDs).Find(&sources).Error; err != nil {
+			return err
+		}
+
+		fields := map[string]any{
+			"manufacturer_id": target.ID,
+			"manufacturer":    target.Name,
+		}
+		for _, model := range []any{&models.Medicine{}, &models.Product{}} {
+			if err := tx.Model(model).Where("manufacturer_id IN ?", sourceIDs).Updates(fields).Error; err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&models.ManufacturerAlias{}).
+			Where("manufacturer_id IN ?", sourceIDs).
+			Update("manufacturer_id", target.ID).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&models.Manufacturer{}, sourceIDs).Error; err != nil {
+			return err
+		}
+
+		aliases := make([]models.ManufacturerAlias, 0, len(sources))
+		for _, source := range sources {
+			aliases = append(aliases, models.ManufacturerAlias{ManufacturerID: target.ID, Name: source.Name})
+		}
+
+		if len(aliases) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&aliases).Error
+	})
+}
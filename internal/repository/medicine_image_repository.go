@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+)
+
+type MedicineImageRepository interface {
+	Create(image *models.MedicineImage) error
+
+	GetByID(medicineID, id uint) (*models.MedicineImage, error)
+
+	ListByMedicineID(medicineID uint) ([]models.MedicineImage, error)
+
+	ListByMedicineIDs(medicineIDs []uint) ([]models.MedicineImage, error)
+
+	Reorder(medicineID uint, imageIDs []uint) error
+
+	SetPrimary(medicineID, id uint) error
+
+	Delete(image *models.MedicineImage) error
+}
+
+type gormMedicineImageRepository struct {
+	db *gorm.DB
+}
+
+func NewMedicineImageRepository(db *gorm.DB) MedicineImageRepository {
+	return &gormMedicineImageRepository{db: db}
+}
+
+func (r *gormMedicineImageRepository) Create(image *models.MedicineImage) error {
+	if image == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var stats struct {
+			Count       int64
+			MaxPosition int
+		}
+		err := tx.Model(&models.MedicineImage{}).
+			Select("count(*) AS count, coalesce(max(position), 0) AS max_position").
+			Where("medicine_id = ?", image.MedicineID).
+			Scan(&stats).Error
+		if err != nil {
+			return err
+		}
+
+		image.Position = stats.MaxPosition + 1
+		if stats.Count == 0 {
+			image.IsPrimary = true
+		}
+
+		if image.IsPrimary && stats.Count > 0 {
+			if err := tx.Model(&models.MedicineImage{}).
+				Where("medicine_id = ?", image.MedicineID).
+				Update("is_primary", false).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(image).Error
+	})
+}
+
+func (r *gormMedicineImageRepository) GetByID(medicineID, id uint) (*models.MedicineImage, error) {
+	var image models.MedicineImage
+
+	if err := r.db.Where("medicine_id = ?", medicineID).First(&image, id).Error; err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *gormMedicineImageRepository) ListByMedicineID(medicineID uint) ([]models.MedicineImage, error) {
+	return r.ListByMedicineIDs([]uint{medicineID})
+}
+
+func (r *gormMedicineImageRepository) ListByMedicineIDs(medicineIDs []uint) ([]models.MedicineImage, error) {
+	var images []models.MedicineImage
+
+	if len(medicineIDs) == 0 {
+		return images, nil
+	}
+
+	if err := r.db.Where("medicine_id IN ?", medicineIDs).Order("medicine_id, position, id").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (r *gormMedicineImageRepository) Reorder(medicineID uint, imageIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range imageIDs {
+			result := tx.Model(&models.MedicineImage{}).
+				Where("id = ? AND medicine_id = ?", id, medicineID).
+				Update("position", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
+func (r *gormMedicineImageRepository) SetPrimary(medicineID, id uint) error {
+	return r.db.Model(&models.MedicineImage{}).
+		Where("medicine_id = ?", medicineID).
+		Update("is_primary", gorm.Expr("id = ?", id)).Error
+}
+
+func (r *gormMedicineImageRepository) Delete(image *models.MedicineImage) error {
+	if image == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(image).Error; err != nil {
+			return err
+		}
+
+		if !image.IsPrimary {
+			return nil
+		}
+
+		var next models.MedicineImage
+		err := tx.Where("medicine_id = ?", image.MedicineID).Order("position, id").First(&next).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return tx.Model(&next).Update("is_primary", true).Error
+	})
+}
@@ -1,30 +1,129 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrBarcodeTaken = errors.New("штрихкод уже привязан к другому лекарству")
+
+const (
+	searchTSQuery              = "websearch_to_tsquery('russian', ?)"
+	nameHeadlineOptions        = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	descriptionHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+type MedicineSort string
+
+const (
+	MedicineSortPrice  MedicineSort = "price"
+	MedicineSortRating MedicineSort = "rating"
+	MedicineSortName   MedicineSort = "name"
+	MedicineSortNewest MedicineSort = "newest"
 )
 
+var medicineSortColumns = map[MedicineSort]string{
+	MedicineSortPrice:  "price",
+	MedicineSortRating: "avg_rating",
+	MedicineSortName:   "name",
+	MedicineSortNewest: "created_at",
+}
+
+func (s MedicineSort) Valid() bool {
+	_, ok := medicineSortColumns[s]
+	return ok
+}
+
+var MedicinePriceBucketBounds = []float64{100, 300, 500, 1000, 3000}
+
+type MedicineFacetRow struct {
+	Facet                string
+	CategoryID           *uint
+	SubcategoryID        *uint
+	Manufacturer         *string
+	PrescriptionRequired *bool
+	InStock              *bool
+	PriceBucket          *int
+	Count                int64
+}
+
+type MedicineSalesRow struct {
+	MedicineID    uint
+	Name          string
+	StockQuantity int
+	ReorderPoint  int
+	TargetStock   int
+	Sold          int64
+}
+
 type MedicineFilter struct {
-	CategoryID    *uint
-	SubcategoryID *uint
-	InStock       *bool
+	Query                string
+	CategoryID           *uint
+	SubcategoryID        *uint
+	InStock              *bool
+	MinPrice             *float64
+	MaxPrice             *float64
+	Manufacturer         *string
+	ManufacturerID       *uint
+	PrescriptionRequired *bool
+	MinRating            *float64
+	Location             *StockLocation
+	SortBy               MedicineSort
+	SortDesc             bool
+	Limit                int
+	Offset               int
 }
 
 type MedicineRepository interface {
-	Create(medicine *models.Medicine) error
+	Create(medicine *models.Medicine, changes MedicineChanges) error
 
 	GetByID(id uint) (*models.Medicine, error)
 
+	GetBySKU(sku string) (*models.Medicine, error)
+
+	GetByBarcode(code string) (*models.Medicine, error)
+
 	Delete(id uint) error
 
-	Update(medicine *models.Medicine) error
+	Update(medicine *models.Medicine, changes MedicineChanges) error
 
 	GetAll() ([]models.Medicine, error)
 
-	List(filter MedicineFilter) ([]models.Medicine, error)
+	GetByIDs(ids []uint) ([]models.Medicine, error)
+
+	List(filter MedicineFilter) ([]models.Medicine, int64, error)
+
+	Suggest(query string, limit int) ([]models.MedicineSuggestion, error)
+
+	Facets(filter MedicineFilter) ([]MedicineFacetRow, error)
+
+	LocalStock(id uint, location StockLocation) (int, error)
+
+	ListBelowReorderPoint() ([]models.Medicine, error)
+
+	ListSalesSince(since time.Time) ([]MedicineSalesRow, error)
+}
+
+type MedicineChanges struct {
+	Actor       string
+	Stock       *int
+	StockReason string
+	Price       *float64
+	PriceSource models.PriceChangeSource
+	PriceReason string
+	Barcodes    *[]string
 }
 
+var medicineServerColumns = []string{"Barcodes", "Images", "stock_quantity", "in_stock", "price", "avg_rating", "review_count"}
+
 type gormMedecineRepository struct {
 	db *gorm.DB
 }
@@ -33,40 +132,238 @@ func NewMedicineRepository(db *gorm.DB) MedicineRepository {
 	return &gormMedecineRepository{db: db}
 }
 
-func (r *gormMedecineRepository) Create(medicine *models.Medicine) error {
+func (r *gormMedecineRepository) Create(medicine *models.Medicine, changes MedicineChanges) error {
 	if medicine == nil {
 		return nil
 	}
 
-	return r.db.Create(&medicine).Error
+	medicine.StockQuantity = 0
+	medicine.InStock = false
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if medicine.ProductID == nil {
+			product := &models.Product{
+				Name:           medicine.Name,
+				Description:    medicine.Description,
+				Manufacturer:   medicine.Manufacturer,
+				ManufacturerID: medicine.ManufacturerID,
+				CategoryID:     medicine.CategoryID,
+				SubcategoryID:  medicine.SubcategoryID,
+			}
+			if err := tx.Create(product).Error; err != nil {
+				return err
+			}
+			medicine.ProductID = &product.ID
+		}
+
+		if err := tx.Create(&medicine).Error; err != nil {
+			if isUniqueViolation(err, "medicine_barcodes") {
+				return ErrBarcodeTaken
+			}
+			return err
+		}
+
+		if err := recordPriceChange(tx, medicine.ID, 0, medicine.Price, changes); err != nil {
+			return err
+		}
+
+		if changes.Stock == nil || *changes.Stock <= 0 {
+			return nil
+		}
+
+		if err := adjustMedicineStock(tx, medicine.ID, *changes.Stock); err != nil {
+			return err
+		}
+		movement, err := recordMovement(tx, medicine.ID, nil, *changes.Stock, movementInfo{
+			Type:   models.MovementReceipt,
+			Actor:  changes.Actor,
+			Reason: changes.StockReason,
+		})
+		if err != nil {
+			return err
+		}
+		medicine.StockQuantity = movement.BalanceAfter
+		medicine.InStock = movement.BalanceAfter > 0
+		return nil
+	})
 }
 
 func (r *gormMedecineRepository) GetByID(id uint) (*models.Medicine, error) {
 	var medicine models.Medicine
 
-	if err := r.db.First(&medicine, id).Error; err != nil {
+	if err := r.db.Preload("Barcodes").First(&medicine, id).Error; err != nil {
 		return nil, err
 	}
 
 	return &medicine, nil
 }
 
-func (r *gormMedecineRepository) Delete(id uint) error {
+func (r *gormMedecineRepository) GetByBarcode(code string) (*models.Medicine, error) {
 	var medicine models.Medicine
 
-	if err := r.db.Delete(&medicine, id).Error; err != nil {
-		return err
+	err := r.db.Preload("Barcodes").
+		Where("id = (SELECT medicine_id FROM medicine_barcodes WHERE code = ?)", code).
+		First(&medicine).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &medicine, nil
+}
+
+func (r *gormMedecineRepository) GetBySKU(sku string) (*models.Medicine, error) {
+	var medicine models.Medicine
+
+	if err := r.db.Where("sku = ?", sku).First(&medicine).Error; err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &medicine, nil
+}
+
+func (r *gormMedecineRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("medicine_id = ?", id).Delete(&models.MedicineBarcode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Medicine{}, id).Error
+	})
 }
 
-func (r *gormMedecineRepository) Update(medicine *models.Medicine) error {
+func (r *gormMedecineRepository) Update(medicine *models.Medicine, changes MedicineChanges) error {
 	if medicine == nil {
 		return nil
 	}
 
-	return r.db.Save(&medicine).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Medicine
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, stock_quantity, price").
+			First(&current, medicine.ID).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Omit(medicineServerColumns...).Save(medicine).Error; err != nil {
+			return err
+		}
+
+		if medicine.ProductID != nil {
+			if err := syncProductFields(tx, medicine); err != nil {
+				return err
+			}
+		}
+
+		if changes.Stock != nil && *changes.Stock != current.StockQuantity {
+			var batches int64
+			if err := tx.Model(&models.Batch{}).Where("medicine_id = ?", medicine.ID).Count(&batches).Error; err != nil {
+				return err
+			}
+			if batches > 0 {
+				return ErrBatchRequired
+			}
+
+			delta := *changes.Stock - current.StockQuantity
+			if err := adjustMedicineStock(tx, medicine.ID, delta); err != nil {
+				return err
+			}
+			_, err := recordMovement(tx, medicine.ID, nil, delta, movementInfo{
+				Type:   models.MovementAdjustment,
+				Actor:  changes.Actor,
+				Reason: changes.StockReason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if changes.Price != nil && *changes.Price != current.Price {
+			if err := tx.Model(&models.Medicine{}).Where("id = ?", medicine.ID).Update("price", *changes.Price).Error; err != nil {
+				return err
+			}
+			if err := recordPriceChange(tx, medicine.ID, current.Price, *changes.Price, changes); err != nil {
+				return err
+			}
+		}
+
+		if changes.Barcodes != nil {
+			barcodes, err := replaceBarcodes(tx, medicine.ID, *changes.Barcodes)
+			if err != nil {
+				return err
+			}
+			medicine.Barcodes = barcodes
+		}
+
+		if err := tx.Select("stock_quantity, in_stock, price").First(&current, medicine.ID).Error; err != nil {
+			return err
+		}
+		medicine.StockQuantity = current.StockQuantity
+		medicine.InStock = current.InStock
+		medicine.Price = current.Price
+		return nil
+	})
+}
+
+func syncProductFields(tx *gorm.DB, medicine *models.Medicine) error {
+	fields := sharedProductFields(&models.Product{
+		Description:    medicine.Description,
+		Manufacturer:   medicine.Manufacturer,
+		ManufacturerID: medicine.ManufacturerID,
+		CategoryID:     medicine.CategoryID,
+		SubcategoryID:  medicine.SubcategoryID,
+	})
+
+	if err := tx.Model(&models.Product{}).Where("id = ?", *medicine.ProductID).Updates(fields).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Medicine{}).
+		Where("product_id = ? AND id <> ?", *medicine.ProductID, medicine.ID).
+		Updates(fields).Error
+}
+
+func recordPriceChange(tx *gorm.DB, medicineID uint, oldPrice, newPrice float64, changes MedicineChanges) error {
+	if oldPrice == newPrice {
+		return nil
+	}
+
+	return tx.Create(&models.PriceChange{
+		MedicineID: medicineID,
+		OldPrice:   oldPrice,
+		NewPrice:   newPrice,
+		Source:     changes.PriceSource,
+		Actor:      strings.TrimSpace(changes.Actor),
+		Reason:     strings.TrimSpace(changes.PriceReason),
+	}).Error
+}
+
+func replaceBarcodes(tx *gorm.DB, medicineID uint, codes []string) ([]models.MedicineBarcode, error) {
+	barcodes := make([]models.MedicineBarcode, 0, len(codes))
+	for _, code := range codes {
+		barcodes = append(barcodes, models.MedicineBarcode{MedicineID: medicineID, Code: code})
+	}
+
+	if err := tx.Where("medicine_id = ?", medicineID).Delete(&models.MedicineBarcode{}).Error; err != nil {
+		return nil, err
+	}
+
+	if len(barcodes) == 0 {
+		return barcodes, nil
+	}
+
+	if err := tx.Create(&barcodes).Error; err != nil {
+		if isUniqueViolation(err, "medicine_barcodes") {
+			return nil, ErrBarcodeTaken
+		}
+		return nil, err
+	}
+	return barcodes, nil
+}
+
+func isUniqueViolation(err error, table string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.TableName == table
 }
 
 func (r *gormMedecineRepository) GetAll() ([]models.Medicine, error) {
@@ -78,11 +375,204 @@ func (r *gormMedecineRepository) GetAll() ([]models.Medicine, error) {
 	return medicines, nil
 }
 
-func (r *gormMedecineRepository) List(filter MedicineFilter) ([]models.Medicine, error) {
+func (r *gormMedecineRepository) GetByIDs(ids []uint) ([]models.Medicine, error) {
+	var medicines []models.Medicine
+
+	if len(ids) == 0 {
+		return medicines, nil
+	}
+
+	if err := r.db.Where("id IN ?", ids).Find(&medicines).Error; err != nil {
+		return nil, err
+	}
+	return medicines, nil
+}
+
+func (r *gormMedecineRepository) List(filter MedicineFilter) ([]models.Medicine, int64, error) {
 	var medicines []models.Medicine
+	var total int64
+
+	query := applyMedicineFilter(r.db.Model(&models.Medicine{}), filter)
+
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = selectMedicineColumns(query, filter)
+
+	if column, ok := medicineSortColumns[filter.SortBy]; ok {
+		direction := "ASC"
+		if filter.SortDesc {
+			direction = "DESC"
+		}
+		query = query.Order(column + " " + direction)
+	} else if filter.Query != "" {
+		query = query.Order("search_rank DESC")
+	}
+	query = query.Order("id ASC")
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
 
-	query := r.db.Model(&models.Medicine{})
+	if err := query.Preload("Barcodes").Find(&medicines).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return medicines, total, nil
+}
+
+func (r *gormMedecineRepository) Suggest(query string, limit int) ([]models.MedicineSuggestion, error) {
+	var suggestions []models.MedicineSuggestion
+
+	err := r.db.Raw(`
+		SELECT type, id, text, score FROM (
+			SELECT 'medicine' AS type, id, name AS text, word_similarity(@q, name) AS score
+			FROM medicines
+			WHERE deleted_at IS NULL AND @q <% name
+			UNION ALL
+			SELECT 'manufacturer' AS type, m.id, m.name AS text,
+				max(greatest(word_similarity(@q, m.name), coalesce(word_similarity(@q, a.name), 0))) AS score
+			FROM manufacturers m
+			LEFT JOIN manufacturer_aliases a ON a.manufacturer_id = m.id
+			WHERE m.deleted_at IS NULL AND (@q <% m.name OR @q <% a.name)
+			GROUP BY m.id, m.name
+			UNION ALL
+			SELECT 'category' AS type, id, name AS text, word_similarity(@q, name) AS score
+			FROM categories
+			WHERE deleted_at IS NULL AND @q <% name
+		) AS suggestions
+		ORDER BY score DESC, text ASC
+		LIMIT @limit`,
+		sql.Named("q", query),
+		sql.Named("limit", limit),
+	).Scan(&suggestions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+func (r *gormMedecineRepository) Facets(filter MedicineFilter) ([]MedicineFacetRow, error) {
+	var rows []MedicineFacetRow
 
+	filtered := applyMedicineFilter(r.db.Model(&models.Medicine{}), filter).
+		Select("category_id, subcategory_id, manufacturer, prescription_required, in_stock, " +
+			"width_bucket(price::float8, " + priceBucketBoundsSQL() + ") AS price_bucket")
+
+	err := r.db.Table("(?) AS filtered", filtered).
+		Select(`CASE
+				WHEN GROUPING(category_id) = 0 THEN 'category'
+				WHEN GROUPING(subcategory_id) = 0 THEN 'subcategory'
+				WHEN GROUPING(manufacturer) = 0 THEN 'manufacturer'
+				WHEN GROUPING(prescription_required) = 0 THEN 'prescription_required'
+				WHEN GROUPING(in_stock) = 0 THEN 'in_stock'
+				ELSE 'price_bucket'
+			END AS facet,
+			category_id, subcategory_id, manufacturer, prescription_required, in_stock, price_bucket,
+			count(*) AS count`).
+		Group("GROUPING SETS ((category_id), (subcategory_id), (manufacturer), (prescription_required), (in_stock), (price_bucket))").
+		Order("facet, count DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *gormMedecineRepository) LocalStock(id uint, location StockLocation) (int, error) {
+	var quantity int
+
+	expr, args := localStockSQL(location)
+	err := r.db.Model(&models.Medicine{}).
+		Select(expr, args...).
+		Where("id = ?", id).
+		Scan(&quantity).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+func (r *gormMedecineRepository) ListBelowReorderPoint() ([]models.Medicine, error) {
+	var medicines []models.Medicine
+
+	err := r.db.Where("reorder_point > 0 AND stock_quantity <= reorder_point").
+		Order("stock_quantity, id").
+		Find(&medicines).Error
+	if err != nil {
+		return nil, err
+	}
+	return medicines, nil
+}
+
+func (r *gormMedecineRepository) ListSalesSince(since time.Time) ([]MedicineSalesRow, error) {
+	var rows []MedicineSalesRow
+
+	sales := r.db.Table("order_items").
+		Select("order_items.medicine_id, sum(order_items.quantity) AS sold").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL AND orders.order_status <> ?", models.Canceled).
+		Where("order_items.deleted_at IS NULL AND order_items.created_at >= ?", since).
+		Group("order_items.medicine_id")
+
+	err := r.db.Table("medicines").
+		Select("medicines.id AS medicine_id, medicines.name, medicines.stock_quantity, "+
+			"medicines.reorder_point, medicines.target_stock, coalesce(sales.sold, 0) AS sold").
+		Joins("LEFT JOIN (?) AS sales ON sales.medicine_id = medicines.id", sales).
+		Where("medicines.deleted_at IS NULL AND (medicines.reorder_point > 0 OR sales.sold > 0)").
+		Order("medicines.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func priceBucketBoundsSQL() string {
+	bounds := make([]string, len(MedicinePriceBucketBounds))
+	for i, bound := range MedicinePriceBucketBounds {
+		bounds[i] = strconv.FormatFloat(bound, 'f', -1, 64)
+	}
+	return "ARRAY[" + strings.Join(bounds, ", ") + "]::float8[]"
+}
+
+func selectMedicineColumns(query *gorm.DB, filter MedicineFilter) *gorm.DB {
+	columns := []string{"medicines.*"}
+	var args []any
+
+	if filter.Query != "" {
+		columns = append(columns,
+			"ts_rank(search_vector, "+searchTSQuery+") AS search_rank",
+			"ts_headline('russian', name, "+searchTSQuery+", ?) AS name_highlight",
+			"ts_headline('russian', description, "+searchTSQuery+", ?) AS description_highlight",
+		)
+		args = append(args,
+			filter.Query,
+			filter.Query, nameHeadlineOptions,
+			filter.Query, descriptionHeadlineOptions,
+		)
+	}
+
+	if filter.Location != nil {
+		expr, exprArgs := localStockSQL(*filter.Location)
+		columns = append(columns, expr+" AS local_stock")
+		args = append(args, exprArgs...)
+	}
+
+	if len(columns) == 1 {
+		return query
+	}
+	return query.Select(strings.Join(columns, ", "), args...)
+}
+
+func applyMedicineFilter(query *gorm.DB, filter MedicineFilter) *gorm.DB {
 	if filter.CategoryID != nil {
 		query = query.Where("category_id = ?", *filter.CategoryID)
 	}
@@ -92,12 +582,43 @@ func (r *gormMedecineRepository) List(filter MedicineFilter) ([]models.Medicine,
 	}
 
 	if filter.InStock != nil {
-		query = query.Where("in_stock = ?", *filter.InStock)
+		if filter.Location != nil {
+			expr, args := localStockSQL(*filter.Location)
+			query = query.Where("("+expr+" > 0) = ?", append(args, *filter.InStock)...)
+		} else {
+			query = query.Where("in_stock = ?", *filter.InStock)
+		}
 	}
 
-	if err := query.Find(&medicines).Error; err != nil {
-		return nil, err
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
 	}
 
-	return medicines, nil
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+
+	if filter.Manufacturer != nil {
+		query = query.Where("lower(manufacturer) = lower(?) OR manufacturer_id IN "+
+			"(SELECT manufacturer_id FROM manufacturer_aliases WHERE lower(name) = lower(?))",
+			*filter.Manufacturer, *filter.Manufacturer)
+	}
+
+	if filter.ManufacturerID != nil {
+		query = query.Where("manufacturer_id = ?", *filter.ManufacturerID)
+	}
+
+	if filter.PrescriptionRequired != nil {
+		query = query.Where("prescription_required = ?", *filter.PrescriptionRequired)
+	}
+
+	if filter.MinRating != nil {
+		query = query.Where("avg_rating >= ?", *filter.MinRating)
+	}
+
+	if filter.Query != "" {
+		query = query.Where("search_vector @@ "+searchTSQuery, filter.Query)
+	}
+
+	return query
 }
@@ -1,19 +1,27 @@
 package repository
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository interface {
 	Create(order *models.Order) error
 
+	CreateFromCart(order *models.Order, cart *models.Cart) error
+
 	GetByID(id uint) (*models.Order, error)
 
 	GetByUserID(userID uint) (*models.Order, error)
 
 	Update(order *models.Order) error
 
+	Cancel(order *models.Order) error
+
 	Delete(id uint) error
 }
 
@@ -31,9 +39,56 @@ func (r *gormOrderRepository) Create(order *models.Order) error {
 	}
 	return r.db.Create(order).Error
 }
+func (r *gormOrderRepository) CreateFromCart(order *models.Order, cart *models.Cart) error {
+	if order == nil || cart == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Create(order).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var items []models.OrderItem
+		for _, cartItem := range cart.Items {
+			allocations, err := allocateBatches(tx, cartItem.MedicineID, order.BranchID, int(cartItem.Quantity), now, movementInfo{
+				Type:   models.MovementSale,
+				Actor:  fmt.Sprintf("user:%d", order.UserID),
+				Reason: fmt.Sprintf("заказ #%d", order.ID),
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, allocation := range allocations {
+				items = append(items, models.OrderItem{
+					OrderID:      order.ID,
+					MedicineID:   cartItem.MedicineID,
+					MedicineName: cartItem.Name,
+					Quantity:     allocation.Quantity,
+					PricePerUnit: cartItem.PricePerUnit,
+					LineTotal:    int64(allocation.Quantity) * cartItem.PricePerUnit,
+					BatchID:      allocation.BatchID,
+					LotNumber:    allocation.LotNumber,
+				})
+			}
+		}
+
+		if len(items) > 0 {
+			if err := tx.Create(&items).Error; err != nil {
+				return err
+			}
+		}
+		order.Items = items
+
+		return tx.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error
+	})
+}
+
 func (r *gormOrderRepository) GetByID(id uint) (*models.Order, error) {
 	var order models.Order
-	if err := r.db.First(&order, id).Error; err != nil {
+	if err := r.db.Preload("Items").First(&order, id).Error; err != nil {
 		return nil, err
 	}
 	return &order, nil
@@ -52,6 +107,58 @@ func (r *gormOrderRepository) Update(order *models.Order) error {
 	}
 	return r.db.Save(order).Error
 }
+func (r *gormOrderRepository) Cancel(order *models.Order) error {
+	if order == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "order_status").
+			First(&current, order.ID).Error
+		if err != nil {
+			return err
+		}
+
+		order.OrderStatus = models.Canceled
+		if err := tx.Omit("Items").Save(order).Error; err != nil {
+			return err
+		}
+		if current.OrderStatus == models.Canceled {
+			return nil
+		}
+
+		now := time.Now()
+		info := movementInfo{
+			Type:   models.MovementReturn,
+			Actor:  fmt.Sprintf("user:%d", order.UserID),
+			Reason: fmt.Sprintf("отмена заказа #%d", order.ID),
+		}
+		for _, item := range order.Items {
+			if item.BatchID == nil {
+				if err := adjustMedicineStock(tx, item.MedicineID, item.Quantity); err != nil {
+					return err
+				}
+			} else {
+				err := tx.Model(&models.Batch{}).
+					Where("id = ?", *item.BatchID).
+					Update("quantity", gorm.Expr("quantity + ?", item.Quantity)).Error
+				if err != nil {
+					return err
+				}
+				if err := syncMedicineStock(tx, item.MedicineID, now); err != nil {
+					return err
+				}
+			}
+
+			if _, err := recordMovement(tx, item.MedicineID, item.BatchID, item.Quantity, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 func (r *gormOrderRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Order{}).Error
 }
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PriceRepository interface {
+	Record(change *models.PriceChange) error
+
+	ListChanges(medicineID uint, limit, offset int) ([]models.PriceChange, int64, error)
+
+	LastChange(medicineID uint) (*models.PriceChange, error)
+
+	CreateScheduled(price *models.ScheduledPrice) error
+
+	GetScheduled(medicineID, id uint) (*models.ScheduledPrice, error)
+
+	ListPendingScheduled(medicineID uint) ([]models.ScheduledPrice, error)
+
+	DeleteScheduled(id uint) error
+
+	ApplyDue(now time.Time) ([]models.PriceChange, error)
+}
+
+type gormPriceRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRepository(db *gorm.DB) PriceRepository {
+	return &gormPriceRepository{db: db}
+}
+
+func (r *gormPriceRepository) Record(change *models.PriceChange) error {
+	if change == nil {
+		return nil
+	}
+
+	return r.db.Create(change).Error
+}
+
+func (r *gormPriceRepository) ListChanges(medicineID uint, limit, offset int) ([]models.PriceChange, int64, error) {
+	var changes []models.PriceChange
+	var total int64
+
+	query := r.db.Model(&models.PriceChange{}).Where("medicine_id = ?", medicineID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&changes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return changes, total, nil
+}
+
+func (r *gormPriceRepository) LastChange(medicineID uint) (*models.PriceChange, error) {
+	var change models.PriceChange
+
+	if err := r.db.Where("medicine_id = ?", medicineID).Order("created_at DESC, id DESC").First(&change).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *gormPriceRepository) CreateScheduled(price *models.ScheduledPrice) error {
+	if price == nil {
+		return nil
+	}
+
+	return r.db.Create(price).Error
+}
+
+func (r *gormPriceRepository) GetScheduled(medicineID, id uint) (*models.ScheduledPrice, error) {
+	var price models.ScheduledPrice
+
+	if err := r.db.Where("medicine_id = ?", medicineID).First(&price, id).Error; err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+func (r *gormPriceRepository) ListPendingScheduled(medicineID uint) ([]models.ScheduledPrice, error) {
+	var prices []models.ScheduledPrice
+
+	err := r.db.Where("medicine_id = ? AND applied_at IS NULL", medicineID).
+		Order("effective_from, id").
+		Find(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (r *gormPriceRepository) DeleteScheduled(id uint) error {
+	return r.db.Delete(&models.ScheduledPrice{}, id).Error
+}
+
+func (r *gormPriceRepository) ApplyDue(now time.Time) ([]models.PriceChange, error) {
+	var changes []models.PriceChange
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var due []models.ScheduledPrice
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("applied_at IS NULL AND effective_from <= ?", now).
+			Order("medicine_id, effective_from, id").
+			Find(&due).Error
+		if err != nil {
+			return err
+		}
+
+		for _, scheduled := range due {
+			if err := tx.Model(&scheduled).Update("applied_at", now).Error; err != nil {
+				return err
+			}
+
+			var medicine models.Medicine
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&medicine, scheduled.MedicineID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			oldPrice := medicine.Price
+			if err := tx.Model(&medicine).Update("price", scheduled.Price).Error; err != nil {
+				return err
+			}
+
+			change := models.PriceChange{
+				MedicineID: scheduled.MedicineID,
+				OldPrice:   oldPrice,
+				NewPrice:   scheduled.Price,
+				Source:     models.PriceSourceSchedule,
+				Actor:      scheduled.Actor,
+				Reason:     scheduled.Reason,
+			}
+			if err := tx.Create(&change).Error; err != nil {
+				return err
+			}
+			changes = append(changes, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
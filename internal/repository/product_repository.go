@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var productSortColumns = map[MedicineSort]string{
+	MedicineSortPrice:  "min(price)",
+	MedicineSortRating: "max(avg_rating)",
+	MedicineSortName:   "min(name)",
+	MedicineSortNewest: "max(created_at)",
+}
+
+type ProductRepository interface {
+	Create(product *models.Product) error
+
+	GetByID(id uint) (*models.Product, error)
+
+	Update(product *models.Product) error
+
+	Delete(id uint) error
+
+	CountVariants(id uint) (int64, error)
+
+	AttachVariants(product *models.Product, medicineIDs []uint) error
+
+	List(filter MedicineFilter) ([]models.Product, int64, error)
+}
+
+type gormProductRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &gormProductRepository{db: db}
+}
+
+func (r *gormProductRepository) Create(product *models.Product) error {
+	if product == nil {
+		return nil
+	}
+
+	return r.db.Omit("Variants").Create(product).Error
+}
+
+func (r *gormProductRepository) GetByID(id uint) (*models.Product, error) {
+	var product models.Product
+
+	err := r.db.Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("price, id")
+	}).First(&product, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *gormProductRepository) Update(product *models.Product) error {
+	if product == nil {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Variants").Save(product).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Medicine{}).
+			Where("product_id = ?", product.ID).
+			Updates(sharedProductFields(product)).Error
+	})
+}
+
+func (r *gormProductRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Product{}, id).Error
+}
+
+func (r *gormProductRepository) CountVariants(id uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.Medicine{}).Where("product_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *gormProductRepository) AttachVariants(product *models.Product, medicineIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var previous []uint
+		err := tx.Model(&models.Medicine{}).
+			Where("id IN ? AND product_id IS NOT NULL AND product_id <> ?", medicineIDs, product.ID).
+			Distinct().
+			Pluck("product_id", &previous).Error
+		if err != nil {
+			return err
+		}
+
+		fields := sharedProductFields(product)
+		fields["product_id"] = product.ID
+
+		if err := tx.Model(&models.Medicine{}).Where("id IN ?", medicineIDs).Updates(fields).Error; err != nil {
+			return err
+		}
+
+		if len(previous) == 0 {
+			return nil
+		}
+
+		return tx.Where("id IN ?", previous).
+			Where("NOT EXISTS (SELECT 1 FROM medicines WHERE medicines.product_id = products.id AND medicines.deleted_at IS NULL)").
+			Delete(&models.Product{}).Error
+	})
+}
+
+func (r *gormProductRepository) List(filter MedicineFilter) ([]models.Product, int64, error) {
+	grouped := applyMedicineFilter(r.db.Model(&models.Medicine{}), filter).
+		Where("product_id IS NOT NULL").
+		Group("product_id")
+
+	var total int64
+	if err := r.db.Table("(?) AS grouped", grouped.Session(&gorm.Session{}).Select("product_id")).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := grouped.Session(&gorm.Session{}).Select("product_id")
+
+	if column, ok := productSortColumns[filter.SortBy]; ok {
+		direction := "ASC"
+		if filter.SortDesc {
+			direction = "DESC"
+		}
+		page = page.Order(column + " " + direction)
+	} else if filter.Query != "" {
+		page = page.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "max(ts_rank(search_vector, " + searchTSQuery + ")) DESC",
+			Vars:               []any{filter.Query},
+			WithoutParentheses: true,
+		}})
+	}
+	page = page.Order("product_id ASC")
+
+	if filter.Limit > 0 {
+		page = page.Limit(filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		page = page.Offset(filter.Offset)
+	}
+
+	var ids []uint
+	if err := page.Pluck("product_id", &ids).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if len(ids) == 0 {
+		return []models.Product{}, total, nil
+	}
+
+	var products []models.Product
+	if err := r.db.Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var variants []models.Medicine
+	query := selectMedicineColumns(applyMedicineFilter(r.db.Model(&models.Medicine{}), filter), filter)
+	if err := query.Where("product_id IN ?", ids).Order("price, id").Find(&variants).Error; err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[uint]*models.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	for _, variant := range variants {
+		if product, ok := byID[*variant.ProductID]; ok {
+			product.Variants = append(product.Variants, variant)
+		}
+	}
+
+	ordered := make([]models.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			ordered = append(ordered, *product)
+		}
+	}
+
+	return ordered, total, nil
+}
+
+func sharedProductFields(product *models.Product) map[string]any {
+	return map[string]any{
+		"description":     product.Description,
+		"manufacturer":    product.Manufacturer,
+		"manufacturer_id": product.ManufacturerID,
+		"category_id":     product.CategoryID,
+		"subcategory_id":  product.SubcategoryID,
+	}
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrPurchaseOrderNotReceivable = errors.New("принимать товар можно только по отправленному заказу поставщику")
+var ErrUnknownPurchaseOrderLine = errors.New("строка не принадлежит заказу поставщику")
+var ErrReceiptExceedsOrdered = errors.New("принятое количество превышает заказанное")
+var ErrLotRequired = errors.New("лекарство учитывается по партиям: укажите lot_number и expiry_date")
+
+type PurchaseOrderFilter struct {
+	SupplierID *uint
+	Status     *models.PurchaseOrderStatus
+}
+
+type PurchaseOrderRepository interface {
+	Create(order *models.PurchaseOrder) error
+
+	GetByID(id uint) (*models.PurchaseOrder, error)
+
+	List(filter PurchaseOrderFilter) ([]models.PurchaseOrder, error)
+
+	UpdateStatus(order *models.PurchaseOrder) error
+
+	Receive(orderID uint, items []models.GoodsReceiptItemRequest, actor string, now time.Time) (*models.GoodsReceipt, error)
+
+	ListReceipts(orderID uint) ([]models.GoodsReceipt, error)
+
+	MarginReport(from, to time.Time) ([]models.MarginReportRow, error)
+}
+
+type gormPurchaseOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderRepository(db *gorm.DB) PurchaseOrderRepository {
+	return &gormPurchaseOrderRepository{db: db}
+}
+
+func (r *gormPurchaseOrderRepository) Create(order *models.PurchaseOrder) error {
+	if order == nil {
+		return nil
+	}
+
+	return r.db.Create(order).Error
+}
+
+func (r *gormPurchaseOrderRepository) GetByID(id uint) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+
+	err := r.db.Preload("Supplier").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *gormPurchaseOrderRepository) List(filter PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	var orders []models.PurchaseOrder
+
+	query := r.db.Preload("Supplier").Preload("Lines")
+
+	if filter.SupplierID != nil {
+		query = query.Where("supplier_id = ?", *filter.SupplierID)
+	}
+
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	if err := query.Order("id DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *gormPurchaseOrderRepository) UpdateStatus(order *models.PurchaseOrder) error {
+	if order == nil {
+		return nil
+	}
+
+	return r.db.Model(order).Select("status", "sent_at", "received_at").Updates(order).Error
+}
+
+func (r *gormPurchaseOrderRepository) Receive(orderID uint, items []models.GoodsReceiptItemRequest, actor string, now time.Time) (*models.GoodsReceipt, error) {
+	receipt := &models.GoodsReceipt{PurchaseOrderID: orderID, Actor: actor}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.PurchaseOrder
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Lines").
+			First(&order, orderID).Error
+		if err != nil {
+			return err
+		}
+
+		if order.Status != models.PurchaseOrderSent && order.Status != models.PurchaseOrderPartiallyReceived {
+			return ErrPurchaseOrderNotReceivable
+		}
+
+		lines := make(map[uint]*models.PurchaseOrderLine, len(order.Lines))
+		for i := range order.Lines {
+			lines[order.Lines[i].ID] = &order.Lines[i]
+		}
+
+		if err := tx.Omit("Items").Create(receipt).Error; err != nil {
+			return err
+		}
+
+		info := movementInfo{
+			Type:   models.MovementReceipt,
+			Actor:  actor,
+			Reason: fmt.Sprintf("приёмка по заказу поставщику #%d", order.ID),
+		}
+
+		for _, item := range items {
+			line, ok := lines[item.LineID]
+			if !ok {
+				return ErrUnknownPurchaseOrderLine
+			}
+			if line.ReceivedQuantity+item.Quantity > line.Quantity {
+				return ErrReceiptExceedsOrdered
+			}
+
+			receiptItem := models.GoodsReceiptItem{
+				GoodsReceiptID:      receipt.ID,
+				PurchaseOrderLineID: line.ID,
+				MedicineID:          line.MedicineID,
+				Quantity:            item.Quantity,
+				PurchasePrice:       line.PurchasePrice,
+				LotNumber:           item.LotNumber,
+				ExpiryDate:          item.ExpiryDate,
+			}
+
+			if item.LotNumber != "" && item.ExpiryDate != nil {
+				batch := &models.Batch{
+					MedicineID: line.MedicineID,
+					BranchID:   order.BranchID,
+					LotNumber:  item.LotNumber,
+					ExpiryDate: *item.ExpiryDate,
+					Quantity:   item.Quantity,
+					ReceivedAt: now,
+				}
+				if err := tx.Create(batch).Error; err != nil {
+					return err
+				}
+				if err := syncMedicineStock(tx, line.MedicineID, now); err != nil {
+					return err
+				}
+				receiptItem.BatchID = &batch.ID
+			} else {
+				var batches int64
+				if err := tx.Model(&models.Batch{}).Where("medicine_id = ?", line.MedicineID).Count(&batches).Error; err != nil {
+					return err
+				}
+				if batches > 0 {
+					return ErrLotRequired
+				}
+				if err := adjustMedicineStock(tx, line.MedicineID, item.Quantity); err != nil {
+					return err
+				}
+			}
+
+			if _, err := recordMovement(tx, line.MedicineID, receiptItem.BatchID, item.Quantity, info); err != nil {
+				return err
+			}
+
+			if err := tx.Create(&receiptItem).Error; err != nil {
+				return err
+			}
+			receipt.Items = append(receipt.Items, receiptItem)
+
+			line.ReceivedQuantity += item.Quantity
+			if err := tx.Model(line).Update("received_quantity", line.ReceivedQuantity).Error; err != nil {
+				return err
+			}
+		}
+
+		status := models.PurchaseOrderReceived
+		for _, line := range order.Lines {
+			if line.ReceivedQuantity < line.Quantity {
+				status = models.PurchaseOrderPartiallyReceived
+				break
+			}
+		}
+
+		updates := map[string]any{"status": status}
+		if status == models.PurchaseOrderReceived {
+			updates["received_at"] = now
+		}
+		return tx.Model(&order).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+func (r *gormPurchaseOrderRepository) ListReceipts(orderID uint) ([]models.GoodsReceipt, error) {
+	var receipts []models.GoodsReceipt
+
+	if err := r.db.Preload("Items").Where("purchase_order_id = ?", orderID).Order("id").Find(&receipts).Error; err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+func (r *gormPurchaseOrderRepository) MarginReport(from, to time.Time) ([]models.MarginReportRow, error) {
+	var rows []models.MarginReportRow
+
+	averageCosts := r.db.Model(&models.GoodsReceiptItem{}).
+		Select("medicine_id, sum(quantity * purchase_price) / nullif(sum(quantity), 0) AS average_cost").
+		Group("medicine_id")
+
+	err := r.db.Table("order_items").
+		Select("order_items.medicine_id, medicines.name AS medicine_name, "+
+			"sum(order_items.quantity) AS sold_quantity, "+
+			"sum(order_items.line_total) / 100.0 AS revenue, "+
+			"coalesce(max(costs.average_cost), 0) AS average_cost, "+
+			"sum(order_items.quantity * coalesce(goods_receipt_items.purchase_price, costs.average_cost, 0)) AS cost").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL AND orders.order_status = ?", models.Completed).
+		Joins("JOIN medicines ON medicines.id = order_items.medicine_id").
+		Joins("LEFT JOIN goods_receipt_items ON goods_receipt_items.batch_id = order_items.batch_id AND goods_receipt_items.deleted_at IS NULL").
+		Joins("LEFT JOIN (?) AS costs ON costs.medicine_id = order_items.medicine_id", averageCosts).
+		Where("order_items.deleted_at IS NULL AND order_items.created_at >= ? AND order_items.created_at < ?", from, to).
+		Group("order_items.medicine_id, medicines.name").
+		Order("order_items.medicine_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].Margin = rows[i].Revenue - rows[i].Cost
+		if rows[i].Revenue > 0 {
+			rows[i].MarginPct = rows[i].Margin / rows[i].Revenue * 100
+		}
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+)
+
+type RecommendationRow struct {
+	MedicineID uint
+	Score      int64
+}
+
+type RecommendationRepository interface {
+	RebuildCoPurchases(now time.Time) (int64, error)
+
+	ListCoPurchased(medicineID uint, minOrders int64, limit int) ([]RecommendationRow, error)
+
+	ListSubcategoryBestsellers(subcategoryID uint, exclude []uint, limit int) ([]RecommendationRow, error)
+}
+
+type gormRecommendationRepository struct {
+	db *gorm.DB
+}
+
+func NewRecommendationRepository(db *gorm.DB) RecommendationRepository {
+	return &gormRecommendationRepository{db: db}
+}
+
+func (r *gormRecommendationRepository) RebuildCoPurchases(now time.Time) (int64, error) {
+	var rows int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM medicine_co_purchases").Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			INSERT INTO medicine_co_purchases (medicine_id, related_id, order_count, updated_at)
+			SELECT a.medicine_id, b.medicine_id, count(DISTINCT a.order_id), ?
+			FROM order_items a
+			JOIN order_items b ON b.order_id = a.order_id AND b.medicine_id <> a.medicine_id AND b.deleted_at IS NULL
+			JOIN orders ON orders.id = a.order_id AND orders.deleted_at IS NULL AND orders.order_status = ?
+			WHERE a.deleted_at IS NULL
+			GROUP BY a.medicine_id, b.medicine_id`, now, models.Completed)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		rows = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rows, nil
+}
+
+func (r *gormRecommendationRepository) ListCoPurchased(medicineID uint, minOrders int64, limit int) ([]RecommendationRow, error) {
+	var rows []RecommendationRow
+
+	err := r.db.Table("medicine_co_purchases AS co").
+		Select("co.related_id AS medicine_id, co.order_count AS score").
+		Joins("JOIN medicines ON medicines.id = co.related_id AND medicines.deleted_at IS NULL").
+		Where("co.medicine_id = ? AND co.order_count >= ?", medicineID, minOrders).
+		Where("medicines.in_stock AND medicines.stock_quantity > 0").
+		Order("co.order_count DESC, co.related_id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *gormRecommendationRepository) ListSubcategoryBestsellers(subcategoryID uint, exclude []uint, limit int) ([]RecommendationRow, error) {
+	var rows []RecommendationRow
+
+	sales := r.db.Table("order_items").
+		Select("order_items.medicine_id, sum(order_items.quantity) AS sold").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL AND orders.order_status = ?", models.Completed).
+		Where("order_items.deleted_at IS NULL").
+		Group("order_items.medicine_id")
+
+	query := r.db.Table("medicines").
+		Select("medicines.id AS medicine_id, coalesce(sales.sold, 0) AS score").
+		Joins("LEFT JOIN (?) AS sales ON sales.medicine_id = medicines.id", sales).
+		Where("medicines.deleted_at IS NULL AND medicines.subcategory_id = ?", subcategoryID).
+		Where("medicines.in_stock AND medicines.stock_quantity > 0")
+
+	if len(exclude) > 0 {
+		query = query.Where("medicines.id NOT IN ?", exclude)
+	}
+
+	err := query.Order("score DESC, medicines.avg_rating DESC, medicines.id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -1,16 +1,61 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrReviewAlreadyExists = errors.New("пользователь уже оставил отзыв на это лекарство")
+
+type ReviewSort string
+
+const (
+	ReviewSortNewest     ReviewSort = "newest"
+	ReviewSortRatingDesc ReviewSort = "rating_desc"
+	ReviewSortRatingAsc  ReviewSort = "rating_asc"
+	ReviewSortHelpful    ReviewSort = "helpful"
 )
 
+var reviewSortOrders = map[ReviewSort]string{
+	ReviewSortNewest:     "created_at DESC, id DESC",
+	ReviewSortRatingDesc: "rating DESC, created_at DESC, id DESC",
+	ReviewSortRatingAsc:  "rating ASC, created_at DESC, id DESC",
+	ReviewSortHelpful:    "helpful_count - not_helpful_count DESC, helpful_count DESC, created_at DESC, id DESC",
+}
+
+type ReviewListFilter struct {
+	Sort   ReviewSort
+	Limit  int
+	Offset int
+}
+
+const refreshVotesSQL = `UPDATE reviews SET
+		helpful_count = (SELECT count(*) FROM review_votes WHERE review_id = @id AND helpful),
+		not_helpful_count = (SELECT count(*) FROM review_votes WHERE review_id = @id AND NOT helpful)
+	WHERE id = @id`
+
+const refreshRatingSQL = `UPDATE medicines SET
+		avg_rating = coalesce((SELECT round(avg(rating)::numeric, 2) FROM reviews WHERE medicine_id = @id AND status = 'approved' AND deleted_at IS NULL), 0),
+		review_count = (SELECT count(*) FROM reviews WHERE medicine_id = @id AND status = 'approved' AND deleted_at IS NULL)
+	WHERE id = @id`
+
 type ReviewRepository interface {
 	Create(review *models.Review) error
 	Update(review *models.Review) error
 	Delete(id uint) error
-	ListByMedicineID(medicineID uint) ([]models.Review, error)
+	ListByMedicineID(medicineID uint, filter ReviewListFilter) ([]models.Review, int64, error)
+	RatingDistribution(medicineID uint) (map[uint]int64, error)
 	GetByID(id uint) (*models.Review, error)
+	GetRating(medicineID uint) (*models.Medicine, error)
+	RecomputeRatings() (int64, error)
+	GetByUserAndMedicine(userID, medicineID uint) (*models.Review, error)
+	HasCompletedPurchase(userID, medicineID uint) (bool, error)
+	ListByStatus(status models.ReviewStatus, filter ReviewListFilter) ([]models.Review, int64, error)
+	UpsertVote(vote *models.ReviewVote) error
+	DeleteVote(reviewID, userID uint) (bool, error)
 }
 
 type gormReviewRepository struct {
@@ -25,28 +70,105 @@ func (r *gormReviewRepository) Create(review *models.Review) error {
 	if review == nil {
 		return nil
 	}
-	return r.db.Create(review).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockMedicine(tx, review.MedicineID); err != nil {
+			return err
+		}
+		if err := tx.Create(review).Error; err != nil {
+			if isUniqueViolation(err, "reviews") {
+				return ErrReviewAlreadyExists
+			}
+			return err
+		}
+		return refreshRating(tx, review.MedicineID)
+	})
 }
 
 func (r *gormReviewRepository) Update(review *models.Review) error {
 	if review == nil {
 		return nil
 	}
-	return r.db.Save(review).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockMedicine(tx, review.MedicineID); err != nil {
+			return err
+		}
+		if err := tx.Omit("helpful_count", "not_helpful_count").Save(review).Error; err != nil {
+			return err
+		}
+		return refreshRating(tx, review.MedicineID)
+	})
 }
 
 func (r *gormReviewRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var review models.Review
+		if err := tx.First(&review, id).Error; err != nil {
+			return err
+		}
+		if err := lockMedicine(tx, review.MedicineID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&review).Error; err != nil {
+			return err
+		}
+		return refreshRating(tx, review.MedicineID)
+	})
+}
+
+func (r *gormReviewRepository) ListByMedicineID(medicineID uint, filter ReviewListFilter) ([]models.Review, int64, error) {
+	query := r.db.Model(&models.Review{}).Where("medicine_id = ? AND status = ?", medicineID, models.ReviewApproved)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := reviewSortOrders[filter.Sort]
+	if !ok {
+		order = reviewSortOrders[ReviewSortNewest]
+	}
+	query = query.Order(order)
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var reviews []models.Review
+	if err := query.Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
 
-	return r.db.Delete(&models.Review{}, id).Error
+	return reviews, total, nil
 }
 
-func (r *gormReviewRepository) ListByMedicineID(medicineID uint) ([]models.Review, error) {
-	var review []models.Review
-	if err := r.db.Where("medicine_id = ?", medicineID).Find(&review).Error; err != nil {
+func (r *gormReviewRepository) RatingDistribution(medicineID uint) (map[uint]int64, error) {
+	var rows []struct {
+		Rating uint
+		Count  int64
+	}
+
+	err := r.db.Model(&models.Review{}).
+		Select("rating, count(*) AS count").
+		Where("medicine_id = ? AND status = ?", medicineID, models.ReviewApproved).
+		Group("rating").
+		Scan(&rows).Error
+	if err != nil {
 		return nil, err
 	}
 
-	return review, nil
+	distribution := make(map[uint]int64, 5)
+	for rating := uint(1); rating <= 5; rating++ {
+		distribution[rating] = 0
+	}
+	for _, row := range rows {
+		distribution[row.Rating] = row.Count
+	}
+
+	return distribution, nil
 }
 
 func (r *gormReviewRepository) GetByID(id uint) (*models.Review, error) {
@@ -59,3 +181,119 @@ func (r *gormReviewRepository) GetByID(id uint) (*models.Review, error) {
 	return &review, err
 
 }
+
+func (r *gormReviewRepository) GetRating(medicineID uint) (*models.Medicine, error) {
+	var medicine models.Medicine
+
+	if err := r.db.Select("id, avg_rating, review_count").First(&medicine, medicineID).Error; err != nil {
+		return nil, err
+	}
+	return &medicine, nil
+}
+
+func (r *gormReviewRepository) RecomputeRatings() (int64, error) {
+	result := r.db.Exec(`
+		UPDATE medicines SET
+			avg_rating = coalesce(stats.avg_rating, 0),
+			review_count = coalesce(stats.review_count, 0)
+		FROM medicines AS m
+		LEFT JOIN (
+			SELECT medicine_id, round(avg(rating)::numeric, 2) AS avg_rating, count(*) AS review_count
+			FROM reviews
+			WHERE status = 'approved' AND deleted_at IS NULL
+			GROUP BY medicine_id
+		) AS stats ON stats.medicine_id = m.id
+		WHERE medicines.id = m.id`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *gormReviewRepository) GetByUserAndMedicine(userID, medicineID uint) (*models.Review, error) {
+	var review models.Review
+
+	if err := r.db.Where("user_id = ? AND medicine_id = ?", userID, medicineID).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *gormReviewRepository) HasCompletedPurchase(userID, medicineID uint) (bool, error) {
+	var count int64
+
+	err := r.db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.user_id = ? AND orders.order_status = ? AND order_items.medicine_id = ?", userID, models.Completed, medicineID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormReviewRepository) ListByStatus(status models.ReviewStatus, filter ReviewListFilter) ([]models.Review, int64, error) {
+	query := r.db.Model(&models.Review{}).Where("status = ?", status)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("flagged DESC, created_at, id")
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var reviews []models.Review
+	if err := query.Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+func (r *gormReviewRepository) UpsertVote(vote *models.ReviewVote) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "review_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+		}).Create(vote).Error
+		if err != nil {
+			return err
+		}
+		return refreshVotes(tx, vote.ReviewID)
+	})
+}
+
+func (r *gormReviewRepository) DeleteVote(reviewID, userID uint) (bool, error) {
+	var deleted bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&models.ReviewVote{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected > 0
+		return refreshVotes(tx, reviewID)
+	})
+
+	return deleted, err
+}
+
+func refreshVotes(tx *gorm.DB, reviewID uint) error {
+	return tx.Exec(refreshVotesSQL, map[string]any{"id": reviewID}).Error
+}
+
+func lockMedicine(tx *gorm.DB, medicineID uint) error {
+	var medicine models.Medicine
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&medicine, medicineID).Error
+}
+
+func refreshRating(tx *gorm.DB, medicineID uint) error {
+	return tx.Exec(refreshRatingSQL, map[string]any{"id": medicineID}).Error
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrBatchRequired = errors.New("для лекарства с партиями нужно указать batch_id")
+
+type movementInfo struct {
+	Type   models.StockMovementType
+	Actor  string
+	Reason string
+}
+
+type StockMovementRepository interface {
+	Adjust(medicineID uint, batchID *uint, delta int, movementType models.StockMovementType, actor, reason string) (*models.StockMovement, error)
+
+	ListByMedicineID(medicineID uint, limit, offset int) ([]models.StockMovement, int64, error)
+
+	Reconcile() ([]models.StockReconciliation, error)
+}
+
+type gormStockMovementRepository struct {
+	db *gorm.DB
+}
+
+func NewStockMovementRepository(db *gorm.DB) StockMovementRepository {
+	return &gormStockMovementRepository{db: db}
+}
+
+func (r *gormStockMovementRepository) Adjust(medicineID uint, batchID *uint, delta int, movementType models.StockMovementType, actor, reason string) (*models.StockMovement, error) {
+	var movement *models.StockMovement
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if batchID != nil {
+			var batch models.Batch
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND medicine_id = ?", *batchID, medicineID).
+				First(&batch).Error
+			if err != nil {
+				return err
+			}
+			if batch.Quantity+delta < 0 {
+				return ErrInsufficientStock
+			}
+			if err := tx.Model(&batch).Update("quantity", batch.Quantity+delta).Error; err != nil {
+				return err
+			}
+			if err := syncMedicineStock(tx, medicineID, now); err != nil {
+				return err
+			}
+		} else {
+			var batches int64
+			if err := tx.Model(&models.Batch{}).Where("medicine_id = ?", medicineID).Count(&batches).Error; err != nil {
+				return err
+			}
+			if batches > 0 {
+				return ErrBatchRequired
+			}
+			if err := adjustMedicineStock(tx, medicineID, delta); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		movement, err = recordMovement(tx, medicineID, batchID, delta, movementInfo{
+			Type:   movementType,
+			Actor:  actor,
+			Reason: reason,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (r *gormStockMovementRepository) ListByMedicineID(medicineID uint, limit, offset int) ([]models.StockMovement, int64, error) {
+	var movements []models.StockMovement
+	var total int64
+
+	query := r.db.Model(&models.StockMovement{}).Where("medicine_id = ?", medicineID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&movements).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+func (r *gormStockMovementRepository) Reconcile() ([]models.StockReconciliation, error) {
+	var rows []models.StockReconciliation
+
+	err := r.db.Raw(`
+		SELECT m.id AS medicine_id,
+			m.name AS medicine_name,
+			coalesce(l.total, 0) AS ledger_total,
+			CASE WHEN b.batches > 0 THEN b.on_hand ELSE m.stock_quantity END AS on_hand
+		FROM medicines m
+		LEFT JOIN (
+			SELECT medicine_id, sum(quantity) AS total FROM stock_movements GROUP BY medicine_id
+		) AS l ON l.medicine_id = m.id
+		LEFT JOIN (
+			SELECT medicine_id, count(*) AS batches, sum(quantity) AS on_hand
+			FROM batches WHERE deleted_at IS NULL GROUP BY medicine_id
+		) AS b ON b.medicine_id = m.id
+		WHERE m.deleted_at IS NULL
+		ORDER BY m.id`,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].Difference = rows[i].LedgerTotal - rows[i].OnHand
+	}
+
+	return rows, nil
+}
+
+func adjustMedicineStock(tx *gorm.DB, medicineID uint, delta int) error {
+	result := tx.Model(&models.Medicine{}).
+		Where("id = ? AND stock_quantity + ? >= 0", medicineID, delta).
+		Updates(map[string]any{
+			"stock_quantity": gorm.Expr("stock_quantity + ?", delta),
+			"in_stock":       gorm.Expr("stock_quantity + ? > 0", delta),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := tx.Model(&models.Medicine{}).Where("id = ?", medicineID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func recordMovement(tx *gorm.DB, medicineID uint, batchID *uint, delta int, info movementInfo) (*models.StockMovement, error) {
+	var balance struct {
+		Batches int64
+		OnHand  int
+		Stock   int
+	}
+
+	err := tx.Raw(`
+		SELECT
+			(SELECT count(*) FROM batches WHERE medicine_id = @id AND deleted_at IS NULL) AS batches,
+			(SELECT coalesce(sum(quantity), 0) FROM batches
+				WHERE medicine_id = @id AND deleted_at IS NULL AND blocked_at IS NULL AND expiry_date > @now) AS on_hand,
+			(SELECT stock_quantity FROM medicines WHERE id = @id) AS stock`,
+		sql.Named("id", medicineID),
+		sql.Named("now", time.Now()),
+	).Scan(&balance).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balanceAfter := balance.Stock
+	if balance.Batches > 0 {
+		balanceAfter = balance.OnHand
+	}
+
+	movement := &models.StockMovement{
+		MedicineID:   medicineID,
+		BatchID:      batchID,
+		Type:         info.Type,
+		Quantity:     delta,
+		BalanceAfter: balanceAfter,
+		Actor:        info.Actor,
+		Reason:       info.Reason,
+	}
+
+	if err := tx.Create(movement).Error; err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
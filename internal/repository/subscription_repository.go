@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SubscriptionRepository interface {
+	Upsert(subscription *models.MedicineSubscription) error
+
+	GetByID(id uint) (*models.MedicineSubscription, error)
+
+	ListByUserID(userID uint) ([]models.MedicineSubscription, error)
+
+	Delete(id uint) error
+
+	ClaimDue(now, staleBefore time.Time) ([]models.DueSubscription, error)
+
+	ReleaseClaim(id uint) error
+}
+
+type gormSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) SubscriptionRepository {
+	return &gormSubscriptionRepository{db: db}
+}
+
+func (r *gormSubscriptionRepository) Upsert(subscription *models.MedicineSubscription) error {
+	if subscription == nil {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "medicine_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"target_price", "updated_at"}),
+	}).Create(subscription).Error
+}
+
+func (r *gormSubscriptionRepository) GetByID(id uint) (*models.MedicineSubscription, error) {
+	var subscription models.MedicineSubscription
+
+	if err := r.db.First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *gormSubscriptionRepository) ListByUserID(userID uint) ([]models.MedicineSubscription, error) {
+	var subscriptions []models.MedicineSubscription
+
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *gormSubscriptionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.MedicineSubscription{}, id).Error
+}
+
+func (r *gormSubscriptionRepository) ClaimDue(now, staleBefore time.Time) ([]models.DueSubscription, error) {
+	var rows []models.DueSubscription
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("medicine_subscriptions AS s").
+			Select("s.*, medicines.name AS medicine_name, medicines.price, medicines.stock_quantity, users.email").
+			Joins("JOIN medicines ON medicines.id = s.medicine_id AND medicines.deleted_at IS NULL").
+			Joins("JOIN users ON users.id = s.user_id AND users.deleted_at IS NULL").
+			Where("(s.type = ? AND medicines.in_stock AND medicines.stock_quantity > 0) OR (s.type = ? AND medicines.price <= s.target_price)",
+				models.SubscriptionBackInStock, models.SubscriptionPriceDrop).
+			Where("s.claimed_at IS NULL OR s.claimed_at < ?", staleBefore).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "s"}, Options: "SKIP LOCKED"}).
+			Order("s.id").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+		}
+		return tx.Model(&models.MedicineSubscription{}).Where("id IN ?", ids).Update("claimed_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *gormSubscriptionRepository) ReleaseClaim(id uint) error {
+	return r.db.Model(&models.MedicineSubscription{}).Where("id = ?", id).Update("claimed_at", nil).Error
+}
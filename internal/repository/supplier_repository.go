@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+)
+
+type SupplierRepository interface {
+	Create(supplier *models.Supplier) error
+
+	GetByID(id uint) (*models.Supplier, error)
+
+	GetAll() ([]models.Supplier, error)
+
+	Update(supplier *models.Supplier) error
+
+	Delete(id uint) error
+
+	CountPurchaseOrders(id uint) (int64, error)
+}
+
+type gormSupplierRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierRepository(db *gorm.DB) SupplierRepository {
+	return &gormSupplierRepository{db: db}
+}
+
+func (r *gormSupplierRepository) Create(supplier *models.Supplier) error {
+	if supplier == nil {
+		return nil
+	}
+
+	return r.db.Create(supplier).Error
+}
+
+func (r *gormSupplierRepository) GetByID(id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+
+	if err := r.db.First(&supplier, id).Error; err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *gormSupplierRepository) GetAll() ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+
+	if err := r.db.Order("name, id").Find(&suppliers).Error; err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+func (r *gormSupplierRepository) Update(supplier *models.Supplier) error {
+	if supplier == nil {
+		return nil
+	}
+
+	return r.db.Save(supplier).Error
+}
+
+func (r *gormSupplierRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Supplier{}, id).Error
+}
+
+func (r *gormSupplierRepository) CountPurchaseOrders(id uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.PurchaseOrder{}).Where("supplier_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
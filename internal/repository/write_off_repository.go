@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNothingToWriteOff = errors.New("нет просроченных партий для списания")
+
+type WriteOffRepository interface {
+	CreateFromExpired(reason, actor string, medicineID *uint, now time.Time) (*models.WriteOff, error)
+
+	GetByID(id uint) (*models.WriteOff, error)
+}
+
+type gormWriteOffRepository struct {
+	db *gorm.DB
+}
+
+func NewWriteOffRepository(db *gorm.DB) WriteOffRepository {
+	return &gormWriteOffRepository{db: db}
+}
+
+func (r *gormWriteOffRepository) CreateFromExpired(reason, actor string, medicineID *uint, now time.Time) (*models.WriteOff, error) {
+	writeOff := &models.WriteOff{Reason: reason}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Medicine").
+			Where("quantity > 0 AND expiry_date <= ?", now)
+		if medicineID != nil {
+			query = query.Where("medicine_id = ?", *medicineID)
+		}
+
+		var batches []models.Batch
+		if err := query.Order("medicine_id, expiry_date, id").Find(&batches).Error; err != nil {
+			return err
+		}
+
+		if len(batches) == 0 {
+			return ErrNothingToWriteOff
+		}
+
+		if err := tx.Omit("Items").Create(writeOff).Error; err != nil {
+			return err
+		}
+
+		synced := make(map[uint]bool)
+		for _, batch := range batches {
+			item := models.WriteOffItem{
+				WriteOffID: writeOff.ID,
+				BatchID:    batch.ID,
+				MedicineID: batch.MedicineID,
+				LotNumber:  batch.LotNumber,
+				ExpiryDate: batch.ExpiryDate,
+				Quantity:   batch.Quantity,
+			}
+			if batch.Medicine != nil {
+				item.MedicineName = batch.Medicine.Name
+			}
+			writeOff.Items = append(writeOff.Items, item)
+
+			err := tx.Model(&models.Batch{}).Where("id = ?", batch.ID).Updates(map[string]any{
+				"quantity":   0,
+				"blocked_at": gorm.Expr("coalesce(blocked_at, ?)", now),
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			if !synced[batch.MedicineID] {
+				synced[batch.MedicineID] = true
+				if err := syncMedicineStock(tx, batch.MedicineID, now); err != nil {
+					return err
+				}
+			}
+
+			batchID := batch.ID
+			_, err = recordMovement(tx, batch.MedicineID, &batchID, -batch.Quantity, movementInfo{
+				Type:   models.MovementWriteOff,
+				Actor:  actor,
+				Reason: fmt.Sprintf("акт списания #%d: %s", writeOff.ID, reason),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&writeOff.Items).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return writeOff, nil
+}
+
+func (r *gormWriteOffRepository) GetByID(id uint) (*models.WriteOff, error) {
+	var writeOff models.WriteOff
+
+	if err := r.db.Preload("Items").First(&writeOff, id).Error; err != nil {
+		return nil, err
+	}
+	return &writeOff, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrActiveIngredientNotFound = errors.New("действующее вещество не найдено")
+var ErrDuplicateIngredient = errors.New("действующее вещество указано несколько раз")
+
+type ActiveIngredientService interface {
+	CreateIngredient(req models.ActiveIngredientCreateRequest) (*models.ActiveIngredient, error)
+
+	GetAllIngredients() ([]models.ActiveIngredient, error)
+
+	GetMedicineIngredients(medicineID uint) ([]models.MedicineIngredient, error)
+
+	SetMedicineIngredients(medicineID uint, req []models.MedicineIngredientRequest) ([]models.MedicineIngredient, error)
+
+	GetAnalogs(medicineID uint) ([]models.MedicineAnalog, error)
+}
+
+type activeIngredientService struct {
+	ingredients repository.ActiveIngredientRepository
+	medicines   repository.MedicineRepository
+}
+
+func NewActiveIngredientService(
+	ingredients repository.ActiveIngredientRepository,
+	medicines repository.MedicineRepository,
+) ActiveIngredientService {
+	return &activeIngredientService{
+		ingredients: ingredients,
+		medicines:   medicines,
+	}
+}
+
+func (s *activeIngredientService) CreateIngredient(req models.ActiveIngredientCreateRequest) (*models.ActiveIngredient, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("поле name не должно быть пустым")
+	}
+
+	ingredient := &models.ActiveIngredient{Name: name}
+	if err := s.ingredients.Create(ingredient); err != nil {
+		return nil, err
+	}
+
+	return ingredient, nil
+}
+
+func (s *activeIngredientService) GetAllIngredients() ([]models.ActiveIngredient, error) {
+	return s.ingredients.GetAll()
+}
+
+func (s *activeIngredientService) GetMedicineIngredients(medicineID uint) ([]models.MedicineIngredient, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	return s.ingredients.ListByMedicineID(medicineID)
+}
+
+func (s *activeIngredientService) SetMedicineIngredients(medicineID uint, req []models.MedicineIngredientRequest) ([]models.MedicineIngredient, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(req))
+	seen := make(map[uint]bool, len(req))
+	for _, item := range req {
+		if item.ActiveIngredientID == 0 {
+			return nil, errors.New("поле active_ingredient_id должно быть больше 0")
+		}
+		if seen[item.ActiveIngredientID] {
+			return nil, ErrDuplicateIngredient
+		}
+		seen[item.ActiveIngredientID] = true
+		ids = append(ids, item.ActiveIngredientID)
+	}
+
+	if len(ids) > 0 {
+		found, err := s.ingredients.GetByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) != len(ids) {
+			return nil, ErrActiveIngredientNotFound
+		}
+	}
+
+	composition := make([]models.MedicineIngredient, 0, len(req))
+	for _, item := range req {
+		composition = append(composition, models.MedicineIngredient{
+			MedicineID:         medicineID,
+			ActiveIngredientID: item.ActiveIngredientID,
+			Strength:           strings.TrimSpace(item.Strength),
+			DosageForm:         strings.TrimSpace(item.DosageForm),
+		})
+	}
+
+	if err := s.ingredients.ReplaceForMedicine(medicineID, composition); err != nil {
+		return nil, err
+	}
+
+	return s.ingredients.ListByMedicineID(medicineID)
+}
+
+func (s *activeIngredientService) GetAnalogs(medicineID uint) ([]models.MedicineAnalog, error) {
+	composition, err := s.GetMedicineIngredients(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	analogs := []models.MedicineAnalog{}
+	if len(composition) == 0 {
+		return analogs, nil
+	}
+
+	matches, err := s.ingredients.FindAnalogs(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.MedicineID)
+	}
+
+	medicines, err := s.medicines.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Medicine, len(medicines))
+	for _, m := range medicines {
+		byID[m.ID] = m
+	}
+
+	for _, match := range matches {
+		medicine, ok := byID[match.MedicineID]
+		if !ok {
+			continue
+		}
+		analogs = append(analogs, models.MedicineAnalog{
+			Medicine:          medicine,
+			ExactMatch:        match.SharedCount == len(composition) && match.IngredientsCount == len(composition),
+			SharedIngredients: match.SharedCount,
+		})
+	}
+
+	sort.SliceStable(analogs, func(i, j int) bool {
+		return analogs[i].Medicine.Price < analogs[j].Medicine.Price
+	})
+
+	return analogs, nil
+}
+
+func (s *activeIngredientService) ensureMedicineExists(medicineID uint) error {
+	if _, err := s.medicines.GetByID(medicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMedicineNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+)
+
+var ErrInvalidBarcode = errors.New("некорректный штрихкод: ожидается GTIN-8, GTIN-12, GTIN-13 или GTIN-14 с верной контрольной цифрой")
+var ErrBarcodeTaken = repository.ErrBarcodeTaken
+
+const gtinLength = 14
+
+func NormalizeGTIN(code string) (string, error) {
+	code = strings.TrimSpace(code)
+
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return "", ErrInvalidBarcode
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidBarcode
+		}
+	}
+
+	if !validGTINChecksum(code) {
+		return "", ErrInvalidBarcode
+	}
+
+	return strings.Repeat("0", gtinLength-len(code)) + code, nil
+}
+
+func validGTINChecksum(code string) bool {
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	check := (10 - sum%10) % 10
+	return check == int(code[len(code)-1]-'0')
+}
+
+func normalizeBarcodes(codes []string) ([]string, error) {
+	seen := make(map[string]bool, len(codes))
+	normalized := make([]string, 0, len(codes))
+
+	for _, code := range codes {
+		gtin, err := NormalizeGTIN(code)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidBarcode, code)
+		}
+		if seen[gtin] {
+			continue
+		}
+		seen[gtin] = true
+		normalized = append(normalized, gtin)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidGTINChecksum(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "GTIN-8", code: "96385074", want: true},
+		{name: "GTIN-8 bad check digit", code: "96385075", want: false},
+		{name: "GTIN-12", code: "036000291452", want: true},
+		{name: "GTIN-12 bad check digit", code: "036000291453", want: false},
+		{name: "GTIN-13", code: "4006381333931", want: true},
+		{name: "GTIN-13 bad check digit", code: "4006381333932", want: false},
+		{name: "GTIN-14", code: "10614141000415", want: true},
+		{name: "GTIN-14 bad check digit", code: "10614141000416", want: false},
+		{name: "all zeros", code: "00000000000000", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validGTINChecksum(tt.code); got != tt.want {
+				t.Errorf("validGTINChecksum(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeGTIN(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+		err  error
+	}{
+		{name: "GTIN-8 padded", code: "96385074", want: "00000096385074"},
+		{name: "GTIN-12 padded", code: "036000291452", want: "00036000291452"},
+		{name: "GTIN-13 padded", code: " 4006381333931 ", want: "04006381333931"},
+		{name: "GTIN-14 unchanged", code: "10614141000415", want: "10614141000415"},
+		{name: "bad check digit", code: "4006381333932", err: ErrInvalidBarcode},
+		{name: "unsupported length", code: "123456789", err: ErrInvalidBarcode},
+		{name: "non-digit", code: "40063813339A1", err: ErrInvalidBarcode},
+		{name: "empty", code: "", err: ErrInvalidBarcode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeGTIN(tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeGTIN(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+type BatchService interface {
+	CreateBatch(medicineID uint, req models.BatchCreateRequest) (*models.Batch, error)
+
+	ListBatches(medicineID uint) ([]models.Batch, error)
+}
+
+type batchService struct {
+	batches   repository.BatchRepository
+	medicines repository.MedicineRepository
+	branches  repository.BranchRepository
+}
+
+func NewBatchService(
+	batches repository.BatchRepository,
+	medicines repository.MedicineRepository,
+	branches repository.BranchRepository,
+) BatchService {
+	return &batchService{
+		batches:   batches,
+		medicines: medicines,
+		branches:  branches,
+	}
+}
+
+func (s *batchService) CreateBatch(medicineID uint, req models.BatchCreateRequest) (*models.Batch, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateBatchCreate(req); err != nil {
+		return nil, err
+	}
+
+	if req.BranchID == nil {
+		return nil, ErrBranchRequired
+	}
+
+	if _, err := s.branches.GetByID(*req.BranchID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBranchNotFound
+		}
+		return nil, err
+	}
+
+	receivedAt := time.Now()
+	if req.ReceivedAt != nil {
+		receivedAt = *req.ReceivedAt
+	}
+
+	batch := &models.Batch{
+		MedicineID: medicineID,
+		BranchID:   req.BranchID,
+		LotNumber:  strings.TrimSpace(req.LotNumber),
+		ExpiryDate: req.ExpiryDate,
+		Quantity:   req.Quantity,
+		ReceivedAt: receivedAt,
+	}
+
+	if err := s.batches.Create(batch, req.Actor); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (s *batchService) ListBatches(medicineID uint) ([]models.Batch, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	return s.batches.ListByMedicineID(medicineID)
+}
+
+func (s *batchService) validateBatchCreate(req models.BatchCreateRequest) error {
+	if strings.TrimSpace(req.LotNumber) == "" {
+		return errors.New("поле lot_number не должно быть пустым")
+	}
+
+	if req.ExpiryDate.IsZero() {
+		return errors.New("поле expiry_date обязательно")
+	}
+
+	if req.Quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	return nil
+}
+
+func (s *batchService) ensureMedicineExists(medicineID uint) error {
+	if _, err := s.medicines.GetByID(medicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMedicineNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrBranchNotFound = errors.New("аптека не найдена")
+var ErrBranchRequired = errors.New("поле branch_id обязательно")
+var ErrBranchInUse = errors.New("в аптеке есть остатки, удаление невозможно")
+var ErrInvalidCoordinates = errors.New("широта должна быть от -90 до 90, долгота от -180 до 180")
+
+const DefaultAvailabilityRadiusKm = 10
+
+type BranchService interface {
+	CreateBranch(req models.BranchCreateRequest) (*models.Branch, error)
+
+	GetBranchByID(id uint) (*models.Branch, error)
+
+	GetAllBranches() ([]models.Branch, error)
+
+	UpdateBranch(id uint, req models.BranchUpdateRequest) (*models.Branch, error)
+
+	DeleteBranch(id uint) error
+
+	GetAvailability(medicineID uint, latitude, longitude *float64) ([]models.BranchAvailability, error)
+}
+
+type branchService struct {
+	branches  repository.BranchRepository
+	medicines repository.MedicineRepository
+}
+
+func NewBranchService(branches repository.BranchRepository, medicines repository.MedicineRepository) BranchService {
+	return &branchService{
+		branches:  branches,
+		medicines: medicines,
+	}
+}
+
+func (s *branchService) CreateBranch(req models.BranchCreateRequest) (*models.Branch, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	if !validCoordinates(req.Latitude, req.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	branch := &models.Branch{
+		Name:      name,
+		Address:   strings.TrimSpace(req.Address),
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+
+	if err := s.branches.Create(branch); err != nil {
+		return nil, err
+	}
+
+	return branch, nil
+}
+
+func (s *branchService) GetBranchByID(id uint) (*models.Branch, error) {
+	branch, err := s.branches.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBranchNotFound
+		}
+		return nil, err
+	}
+
+	return branch, nil
+}
+
+func (s *branchService) GetAllBranches() ([]models.Branch, error) {
+	return s.branches.GetAll()
+}
+
+func (s *branchService) UpdateBranch(id uint, req models.BranchUpdateRequest) (*models.Branch, error) {
+	branch, err := s.GetBranchByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, ErrEmptyName
+		}
+		branch.Name = name
+	}
+
+	if req.Address != nil {
+		branch.Address = strings.TrimSpace(*req.Address)
+	}
+
+	if req.Latitude != nil {
+		branch.Latitude = *req.Latitude
+	}
+
+	if req.Longitude != nil {
+		branch.Longitude = *req.Longitude
+	}
+
+	if !validCoordinates(branch.Latitude, branch.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	if err := s.branches.Update(branch); err != nil {
+		return nil, err
+	}
+
+	return branch, nil
+}
+
+func (s *branchService) DeleteBranch(id uint) error {
+	if _, err := s.GetBranchByID(id); err != nil {
+		return err
+	}
+
+	count, err := s.branches.CountBatches(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrBranchInUse
+	}
+
+	return s.branches.Delete(id)
+}
+
+func (s *branchService) GetAvailability(medicineID uint, latitude, longitude *float64) ([]models.BranchAvailability, error) {
+	if _, err := s.medicines.GetByID(medicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMedicineNotFound
+		}
+		return nil, err
+	}
+
+	if latitude != nil && !validCoordinates(*latitude, *longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	availability, err := s.branches.Availability(medicineID, latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+	if availability == nil {
+		availability = []models.BranchAvailability{}
+	}
+
+	return availability, nil
+}
+
+func validCoordinates(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+func normalizeStockLocation(location *repository.StockLocation) {
+	if location != nil && location.RadiusKm <= 0 {
+		location.RadiusKm = DefaultAvailabilityRadiusKm
+	}
+}
@@ -32,13 +32,14 @@ func (s *cartItemService) AddItem(userID uint, req models.CartCreateItemRequest)
 		return nil, ErrInvalidQuantity
 	}
 
-	med, err := s.medicine.GetByID(req.MedicineID)
+	med, err := s.findMedicine(req)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrMedicineMissing
 		}
 		return nil, err
 	}
+	req.MedicineID = med.ID
 
 	if int(req.Quantity) > med.StockQuantity {
 		return nil, ErrOutOfStock
@@ -80,6 +81,7 @@ func (s *cartItemService) AddItem(userID uint, req models.CartCreateItemRequest)
 		}
 	} else {
 		item := &models.CartItem{
+			CartID:       cart.ID,
 			MedicineID:   req.MedicineID,
 			Name:         med.Name,
 			Quantity:     req.Quantity,
@@ -106,3 +108,15 @@ func (s *cartItemService) AddItem(userID uint, req models.CartCreateItemRequest)
 		UserID: updatedCart.UserID,
 	}, nil
 }
+
+func (s *cartItemService) findMedicine(req models.CartCreateItemRequest) (*models.Medicine, error) {
+	if req.Barcode == "" {
+		return s.medicine.GetByID(req.MedicineID)
+	}
+
+	gtin, err := NormalizeGTIN(req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+	return s.medicine.GetByBarcode(gtin)
+}
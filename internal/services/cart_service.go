@@ -18,16 +18,20 @@ var (
 
 type CartService interface {
 	Create(userID uint) (*models.Cart, error)
-	GetCart(userID uint) (*models.UpdateCart, error)
+	GetCart(userID uint) (*models.CartResponse, error)
 	ClearCart(userID uint) error
 }
 
 type cartService struct {
-	cartRepo repository.CartRepository
+	cartRepo     repository.CartRepository
+	interactions InteractionService
 }
 
-func NewCartService(cartRepo repository.CartRepository) CartService {
-	return &cartService{cartRepo: cartRepo}
+func NewCartService(cartRepo repository.CartRepository, interactions InteractionService) CartService {
+	return &cartService{
+		cartRepo:     cartRepo,
+		interactions: interactions,
+	}
 }
 func (s *cartService) Create(id uint) (*models.Cart, error) {
 	_, err := s.cartRepo.GetByUserID(id)
@@ -45,7 +49,7 @@ func (s *cartService) Create(id uint) (*models.Cart, error) {
 	return cart, nil
 }
 
-func (s *cartService) GetCart(userID uint) (*models.UpdateCart, error) {
+func (s *cartService) GetCart(userID uint) (*models.CartResponse, error) {
 
 	cart, err := s.cartRepo.GetByUserID(userID)
 	if err != nil {
@@ -60,11 +64,19 @@ func (s *cartService) GetCart(userID uint) (*models.UpdateCart, error) {
 		total += cart.Items[i].LineTotal
 	}
 
-	updateCart := &models.UpdateCart{
-		UserID: cart.UserID,
+	warnings, err := s.interactions.CheckItems(cart.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.CartResponse{
+		UserID:       cart.UserID,
+		Items:        cart.Items,
+		TotalPrice:   total,
+		Interactions: warnings,
 	}
 
-	return updateCart, nil
+	return response, nil
 }
 
 func (s *cartService) ClearCart(userID uint) error {
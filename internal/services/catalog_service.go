@@ -0,0 +1,462 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+var ErrImportJobNotFound = errors.New("задача импорта не найдена")
+var ErrUnsupportedCatalogFormat = errors.New("поддерживаются только форматы csv и xlsx")
+var ErrEmptyCatalogFile = errors.New("файл каталога пуст")
+var ErrInvalidCatalogFile = errors.New("не удалось прочитать файл каталога")
+
+const (
+	CatalogFormatCSV  = "csv"
+	CatalogFormatXLSX = "xlsx"
+)
+
+var catalogColumns = []string{
+	"sku",
+	"name",
+	"description",
+	"price",
+	"stock_quantity",
+	"category",
+	"subcategory",
+	"manufacturer",
+	"prescription_required",
+}
+
+var requiredCatalogColumns = []string{"sku", "name", "price", "category", "subcategory"}
+
+type CatalogService interface {
+	PrepareImport(fileName string, r io.Reader) (*models.ImportJob, [][]string, error)
+
+	RunImport(job *models.ImportJob, records [][]string) error
+
+	StartImport(fileName string, r io.Reader) (*models.ImportJob, error)
+
+	GetImportJob(id uint) (*models.ImportJob, error)
+
+	FailInterruptedImports() (int64, error)
+
+	Export(w io.Writer, format string, filter repository.MedicineFilter) error
+}
+
+type catalogService struct {
+	medicineService MedicineService
+	medicines       repository.MedicineRepository
+	categories      repository.CategoryRepository
+	jobs            repository.ImportJobRepository
+	logger          *slog.Logger
+}
+
+func NewCatalogService(
+	medicineService MedicineService,
+	medicines repository.MedicineRepository,
+	categories repository.CategoryRepository,
+	jobs repository.ImportJobRepository,
+) CatalogService {
+	return &catalogService{
+		medicineService: medicineService,
+		medicines:       medicines,
+		categories:      categories,
+		jobs:            jobs,
+		logger:          slog.Default(),
+	}
+}
+
+func CatalogFormatFromFileName(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return CatalogFormatCSV, nil
+	case ".xlsx":
+		return CatalogFormatXLSX, nil
+	}
+	return "", ErrUnsupportedCatalogFormat
+}
+
+func (s *catalogService) PrepareImport(fileName string, r io.Reader) (*models.ImportJob, [][]string, error) {
+	format, err := CatalogFormatFromFileName(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := readCatalogRecords(format, r)
+	if err != nil {
+		if errors.Is(err, ErrEmptyCatalogFile) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCatalogFile, err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil, ErrEmptyCatalogFile
+	}
+
+	if _, err := catalogHeaderIndex(records[0]); err != nil {
+		return nil, nil, err
+	}
+
+	job := &models.ImportJob{
+		FileName:  filepath.Base(fileName),
+		Format:    format,
+		Status:    models.ImportJobPending,
+		TotalRows: len(records) - 1,
+	}
+
+	if err := s.jobs.Create(job); err != nil {
+		return nil, nil, err
+	}
+
+	return job, records, nil
+}
+
+func (s *catalogService) StartImport(fileName string, r io.Reader) (*models.ImportJob, error) {
+	job, records, err := s.PrepareImport(fileName, r)
+	if err != nil {
+		return nil, err
+	}
+
+	go func(job models.ImportJob) {
+		if err := s.RunImport(&job, records); err != nil {
+			s.logger.Error("catalog_service.StartImport: import failed",
+				slog.Uint64("job_id", uint64(job.ID)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}(*job)
+
+	return job, nil
+}
+
+func (s *catalogService) RunImport(job *models.ImportJob, records [][]string) error {
+	job.Status = models.ImportJobRunning
+	if err := s.jobs.Update(job); err != nil {
+		return err
+	}
+
+	rowErrors, err := s.importRecords(job, records)
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = models.ImportJobCompleted
+	if err != nil {
+		job.Status = models.ImportJobFailed
+		job.Error = err.Error()
+	}
+
+	if finishErr := s.jobs.Finish(job, rowErrors); finishErr != nil {
+		return finishErr
+	}
+	return err
+}
+
+func (s *catalogService) importRecords(job *models.ImportJob, records [][]string) ([]models.ImportRowError, error) {
+	var rowErrors []models.ImportRowError
+
+	if len(records) == 0 {
+		return nil, ErrEmptyCatalogFile
+	}
+
+	index, err := catalogHeaderIndex(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := s.newCategoryResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	actor := fmt.Sprintf("import:%d", job.ID)
+
+	for i, record := range records[1:] {
+		rowNumber := i + 2
+		value := func(column string) string {
+			position, ok := index[column]
+			if !ok || position >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[position])
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		req, updateStock, err := resolver.buildRequest(value)
+		if err == nil {
+			req.Actor = actor
+			var created bool
+			created, err = s.medicineService.ImportMedicine(req, updateStock)
+			if err == nil {
+				if created {
+					job.Created++
+				} else {
+					job.Updated++
+				}
+				continue
+			}
+		}
+
+		job.Failed++
+		rowErrors = append(rowErrors, models.ImportRowError{
+			Row:     rowNumber,
+			SKU:     value("sku"),
+			Message: err.Error(),
+		})
+	}
+
+	return rowErrors, nil
+}
+
+func (s *catalogService) GetImportJob(id uint) (*models.ImportJob, error) {
+	job, err := s.jobs.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (s *catalogService) FailInterruptedImports() (int64, error) {
+	return s.jobs.FailUnfinished("импорт прерван перезапуском сервера", time.Now())
+}
+
+func (s *catalogService) Export(w io.Writer, format string, filter repository.MedicineFilter) error {
+	if format != CatalogFormatCSV && format != CatalogFormatXLSX {
+		return ErrUnsupportedCatalogFormat
+	}
+
+	filter.Limit = 0
+	filter.Offset = 0
+	medicines, _, err := s.medicines.List(filter)
+	if err != nil {
+		return err
+	}
+
+	resolver, err := s.newCategoryResolver()
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(medicines)+1)
+	rows = append(rows, catalogColumns)
+	for _, medicine := range medicines {
+		sku := ""
+		if medicine.SKU != nil {
+			sku = *medicine.SKU
+		}
+
+		rows = append(rows, []string{
+			sku,
+			medicine.Name,
+			medicine.Description,
+			strconv.FormatFloat(medicine.Price, 'f', -1, 64),
+			strconv.Itoa(medicine.StockQuantity),
+			resolver.categoryNames[medicine.CategoryID],
+			resolver.subcategoryNames[medicine.SubcategoryID],
+			medicine.Manufacturer,
+			strconv.FormatBool(medicine.PrescriptionRequired),
+		})
+	}
+
+	if format == CatalogFormatCSV {
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		values := make([]any, len(row))
+		for j, value := range row {
+			values[j] = value
+		}
+		if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+
+	return file.Write(w)
+}
+
+type categoryResolver struct {
+	categoryIDs      map[string]uint
+	subcategoryIDs   map[uint]map[string]uint
+	categoryNames    map[uint]string
+	subcategoryNames map[uint]string
+}
+
+func (s *catalogService) newCategoryResolver() (*categoryResolver, error) {
+	categories, err := s.categories.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	subcategories, err := s.categories.GetAllSubcategories()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &categoryResolver{
+		categoryIDs:      make(map[string]uint, len(categories)),
+		subcategoryIDs:   make(map[uint]map[string]uint),
+		categoryNames:    make(map[uint]string, len(categories)),
+		subcategoryNames: make(map[uint]string, len(subcategories)),
+	}
+
+	for _, category := range categories {
+		resolver.categoryIDs[normalizeCatalogName(category.Name)] = category.ID
+		resolver.categoryNames[category.ID] = category.Name
+	}
+
+	for _, subcategory := range subcategories {
+		if resolver.subcategoryIDs[subcategory.CategoryID] == nil {
+			resolver.subcategoryIDs[subcategory.CategoryID] = make(map[string]uint)
+		}
+		resolver.subcategoryIDs[subcategory.CategoryID][normalizeCatalogName(subcategory.Name)] = subcategory.ID
+		resolver.subcategoryNames[subcategory.ID] = subcategory.Name
+	}
+
+	return resolver, nil
+}
+
+func (r *categoryResolver) buildRequest(value func(string) string) (models.MedicineCreateRequest, bool, error) {
+	req := models.MedicineCreateRequest{
+		SKU:          value("sku"),
+		Name:         value("name"),
+		Description:  value("description"),
+		Manufacturer: value("manufacturer"),
+	}
+
+	if req.SKU == "" {
+		return req, false, errors.New("поле sku не должно быть пустым")
+	}
+
+	price, err := strconv.ParseFloat(strings.ReplaceAll(value("price"), ",", "."), 64)
+	if err != nil {
+		return req, false, errors.New("некорректная цена")
+	}
+	req.Price = price
+
+	updateStock := false
+	if raw := value("stock_quantity"); raw != "" {
+		stock, err := strconv.Atoi(raw)
+		if err != nil {
+			return req, false, errors.New("некорректное количество на складе")
+		}
+		req.StockQuantity = stock
+		updateStock = true
+	}
+
+	if raw := value("prescription_required"); raw != "" {
+		prescription, err := parseCatalogBool(raw)
+		if err != nil {
+			return req, false, err
+		}
+		req.PrescriptionRequired = prescription
+	}
+
+	categoryName := value("category")
+	categoryID, ok := r.categoryIDs[normalizeCatalogName(categoryName)]
+	if !ok {
+		return req, false, fmt.Errorf("категория %q не найдена", categoryName)
+	}
+	req.CategoryID = categoryID
+
+	subcategoryName := value("subcategory")
+	subcategoryID, ok := r.subcategoryIDs[categoryID][normalizeCatalogName(subcategoryName)]
+	if !ok {
+		return req, false, fmt.Errorf("подкатегория %q не найдена в категории %q", subcategoryName, categoryName)
+	}
+	req.SubcategoryID = subcategoryID
+
+	return req, updateStock, nil
+}
+
+func readCatalogRecords(format string, r io.Reader) ([][]string, error) {
+	if format == CatalogFormatCSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	}
+
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmptyCatalogFile
+	}
+
+	return file.GetRows(sheets[0])
+}
+
+func catalogHeaderIndex(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		index[column] = i
+	}
+
+	for _, column := range requiredCatalogColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: в файле нет обязательной колонки %s", ErrInvalidCatalogFile, column)
+		}
+	}
+
+	return index, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeCatalogName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func parseCatalogBool(raw string) (bool, error) {
+	switch strings.ToLower(raw) {
+	case "1", "true", "yes", "да":
+		return true, nil
+	case "0", "false", "no", "нет":
+		return false, nil
+	}
+	return false, errors.New("некорректное значение prescription_required")
+}
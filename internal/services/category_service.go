@@ -7,10 +7,16 @@ import (
 
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
 	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
 )
 
 var ErrCategoryNotFound = errors.New("категория не найдена")
 var ErrSubcategoryNotFound = errors.New("подкатегория не найдена")
+var ErrCategoryInUse = errors.New("категория используется лекарствами, укажите reassign_to")
+var ErrSubcategoryInUse = errors.New("подкатегория используется лекарствами, укажите reassign_to")
+var ErrInvalidReassignTarget = errors.New("нельзя переназначить лекарства на удаляемую запись")
+var ErrEmptyName = errors.New("поле name не должно быть пустым")
+var ErrEmptyCategoryID = errors.New("category_id не может быть пустым")
 
 type CategoryService interface {
 	CreateCategory(req models.CreateCategory) (*models.Category, error)
@@ -20,6 +26,16 @@ type CategoryService interface {
 	CreateSubcategory(req models.CreateSubcategory) (*models.Subcategory, error)
 
 	GetSubcategoriesByCategoryID(CategoryID uint) ([]models.Subcategory, error)
+
+	UpdateCategory(id uint, req models.UpdateCategory) (*models.Category, error)
+
+	DeleteCategory(id uint, reassignTo *uint) error
+
+	UpdateSubcategory(id uint, req models.UpdateSubcategory) (*models.Subcategory, error)
+
+	DeleteSubcategory(id uint, reassignTo *uint) error
+
+	GetTree() ([]models.CategoryTreeNode, error)
 }
 
 type categoryService struct {
@@ -121,21 +137,224 @@ func (s *categoryService) GetSubcategoriesByCategoryID(CategoryID uint) ([]model
 	return subs, nil
 }
 
+func (s *categoryService) UpdateCategory(id uint, req models.UpdateCategory) (*models.Category, error) {
+	s.logger.Info("category_service.UpdateCategory: updating category", slog.Uint64("id", uint64(id)))
+
+	category, err := s.getCategory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, ErrEmptyName
+		}
+		category.Name = name
+	}
+
+	if err := s.categories.UpdateCategory(category); err != nil {
+		s.logger.Error("category_service.UpdateCategory: failed to update category", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	s.logger.Info("category_service.UpdateCategory: category updated successfully", slog.Uint64("id", uint64(id)))
+	return category, nil
+}
+
+func (s *categoryService) DeleteCategory(id uint, reassignTo *uint) error {
+	s.logger.Info("category_service.DeleteCategory: deleting category", slog.Uint64("id", uint64(id)))
+
+	if _, err := s.getCategory(id); err != nil {
+		return err
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return ErrInvalidReassignTarget
+		}
+		if _, err := s.getCategory(*reassignTo); err != nil {
+			return err
+		}
+	} else {
+		count, err := s.categories.CountMedicinesByCategoryID(id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			s.logger.Warn("category_service.DeleteCategory: category is in use", slog.Uint64("id", uint64(id)), slog.Int64("medicines", count))
+			return ErrCategoryInUse
+		}
+	}
+
+	if err := s.categories.DeleteCategory(id, reassignTo); err != nil {
+		s.logger.Error("category_service.DeleteCategory: failed to delete category", slog.String("error", err.Error()))
+		return err
+	}
+
+	s.logger.Info("category_service.DeleteCategory: category deleted successfully", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (s *categoryService) UpdateSubcategory(id uint, req models.UpdateSubcategory) (*models.Subcategory, error) {
+	s.logger.Info("category_service.UpdateSubcategory: updating subcategory", slog.Uint64("id", uint64(id)))
+
+	sub, err := s.getSubcategory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, ErrEmptyName
+		}
+		sub.Name = name
+	}
+
+	if req.CategoryID != nil {
+		if _, err := s.getCategory(*req.CategoryID); err != nil {
+			return nil, err
+		}
+		sub.CategoryID = *req.CategoryID
+	}
+
+	if err := s.categories.UpdateSubcategory(sub); err != nil {
+		s.logger.Error("category_service.UpdateSubcategory: failed to update subcategory", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	s.logger.Info("category_service.UpdateSubcategory: subcategory updated successfully", slog.Uint64("id", uint64(id)))
+	return sub, nil
+}
+
+func (s *categoryService) DeleteSubcategory(id uint, reassignTo *uint) error {
+	s.logger.Info("category_service.DeleteSubcategory: deleting subcategory", slog.Uint64("id", uint64(id)))
+
+	if _, err := s.getSubcategory(id); err != nil {
+		return err
+	}
+
+	var target *models.Subcategory
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return ErrInvalidReassignTarget
+		}
+		sub, err := s.getSubcategory(*reassignTo)
+		if err != nil {
+			return err
+		}
+		target = sub
+	} else {
+		count, err := s.categories.CountMedicinesBySubcategoryID(id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			s.logger.Warn("category_service.DeleteSubcategory: subcategory is in use", slog.Uint64("id", uint64(id)), slog.Int64("medicines", count))
+			return ErrSubcategoryInUse
+		}
+	}
+
+	if err := s.categories.DeleteSubcategory(id, target); err != nil {
+		s.logger.Error("category_service.DeleteSubcategory: failed to delete subcategory", slog.String("error", err.Error()))
+		return err
+	}
+
+	s.logger.Info("category_service.DeleteSubcategory: subcategory deleted successfully", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (s *categoryService) GetTree() ([]models.CategoryTreeNode, error) {
+	s.logger.Info("category_service.GetTree: building category tree")
+
+	categories, err := s.categories.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	subcategories, err := s.categories.GetAllSubcategories()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.categories.CountMedicinesGrouped()
+	if err != nil {
+		return nil, err
+	}
+
+	categoryCounts := make(map[uint]int64)
+	subcategoryCounts := make(map[uint]int64)
+	for _, c := range counts {
+		categoryCounts[c.CategoryID] += c.Count
+		subcategoryCounts[c.SubcategoryID] += c.Count
+	}
+
+	tree := make([]models.CategoryTreeNode, 0, len(categories))
+	index := make(map[uint]int, len(categories))
+	for _, c := range categories {
+		index[c.ID] = len(tree)
+		tree = append(tree, models.CategoryTreeNode{
+			ID:            c.ID,
+			Name:          c.Name,
+			MedicineCount: categoryCounts[c.ID],
+			Subcategories: []models.SubcategoryTreeNode{},
+		})
+	}
+
+	for _, sub := range subcategories {
+		i, ok := index[sub.CategoryID]
+		if !ok {
+			continue
+		}
+		tree[i].Subcategories = append(tree[i].Subcategories, models.SubcategoryTreeNode{
+			ID:            sub.ID,
+			Name:          sub.Name,
+			MedicineCount: subcategoryCounts[sub.ID],
+		})
+	}
+
+	s.logger.Info("category_service.GetTree: category tree built successfully", slog.Int("count", len(tree)))
+	return tree, nil
+}
+
+func (s *categoryService) getCategory(id uint) (*models.Category, error) {
+	category, err := s.categories.GetCategoryByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *categoryService) getSubcategory(id uint) (*models.Subcategory, error) {
+	sub, err := s.categories.GetSubcategoryByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubcategoryNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
 func (s *categoryService) validateCategoryCreate(req models.CreateCategory) error {
 	if strings.TrimSpace(req.Name) == "" {
 		s.logger.Warn("category_service.validateCategoryCreate: empty name provided")
-		return errors.New("поле name не должно быть пустым")
+		return ErrEmptyName
 	}
 	return nil
 }
 
 func (s *categoryService) validateSubcategoryCreate(req models.CreateSubcategory) error {
 	if strings.TrimSpace(req.Name) == "" {
-		return errors.New("поле name не должно быть пустым")
+		return ErrEmptyName
 	}
 
 	if req.CategoryID == 0 {
-		return errors.New("category_id не может быть пустым")
+		return ErrEmptyCategoryID
 	}
 
 	return nil
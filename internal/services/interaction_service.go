@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+)
+
+var ErrInteractionsNotAcknowledged = errors.New("в корзине есть опасные взаимодействия лекарств, требуется подтверждение")
+
+type InteractionsNotAcknowledgedError struct {
+	Warnings []models.InteractionWarning
+}
+
+func (e *InteractionsNotAcknowledgedError) Error() string {
+	return ErrInteractionsNotAcknowledged.Error()
+}
+
+func (e *InteractionsNotAcknowledgedError) Unwrap() error {
+	return ErrInteractionsNotAcknowledged
+}
+
+type InteractionService interface {
+	CheckItems(items []models.CartItem) ([]models.InteractionWarning, error)
+}
+
+type interactionService struct {
+	ingredients   repository.ActiveIngredientRepository
+	interactions  repository.InteractionRepository
+	blockSeverity models.InteractionSeverity
+}
+
+func NewInteractionService(
+	ingredients repository.ActiveIngredientRepository,
+	interactions repository.InteractionRepository,
+	blockSeverity models.InteractionSeverity,
+) InteractionService {
+	if !blockSeverity.Valid() {
+		blockSeverity = models.SeverityMajor
+	}
+	return &interactionService{
+		ingredients:   ingredients,
+		interactions:  interactions,
+		blockSeverity: blockSeverity,
+	}
+}
+
+func (s *interactionService) CheckItems(items []models.CartItem) ([]models.InteractionWarning, error) {
+	warnings := []models.InteractionWarning{}
+
+	names := make(map[uint]string, len(items))
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		if _, ok := names[item.MedicineID]; ok {
+			continue
+		}
+		names[item.MedicineID] = item.Name
+		ids = append(ids, item.MedicineID)
+	}
+
+	if len(ids) < 2 {
+		return warnings, nil
+	}
+
+	composition, err := s.ingredients.ListByMedicineIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	medicinesByIngredient := make(map[string][]uint)
+	var ingredientNames []string
+	for _, c := range composition {
+		if c.ActiveIngredient == nil {
+			continue
+		}
+		key := strings.ToLower(c.ActiveIngredient.Name)
+		if _, ok := medicinesByIngredient[key]; !ok {
+			ingredientNames = append(ingredientNames, c.ActiveIngredient.Name)
+		}
+		medicinesByIngredient[key] = append(medicinesByIngredient[key], c.MedicineID)
+	}
+
+	interactions, err := s.interactions.FindByIngredients(ingredientNames)
+	if err != nil {
+		return nil, err
+	}
+
+	type pairKey struct {
+		first, second            uint
+		ingredientA, ingredientB string
+	}
+	seen := make(map[pairKey]bool)
+	for _, interaction := range interactions {
+		for _, a := range medicinesByIngredient[strings.ToLower(interaction.IngredientA)] {
+			for _, b := range medicinesByIngredient[strings.ToLower(interaction.IngredientB)] {
+				if a == b {
+					continue
+				}
+				first, second := a, b
+				if first > second {
+					first, second = second, first
+				}
+				key := pairKey{first, second, interaction.IngredientA, interaction.IngredientB}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				warnings = append(warnings, models.InteractionWarning{
+					MedicineAID:    a,
+					MedicineAName:  names[a],
+					MedicineBID:    b,
+					MedicineBName:  names[b],
+					IngredientA:    interaction.IngredientA,
+					IngredientB:    interaction.IngredientB,
+					Severity:       interaction.Severity,
+					Description:    interaction.Description,
+					BlocksCheckout: interaction.Severity.Rank() >= s.blockSeverity.Rank(),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Severity.Rank() > warnings[j].Severity.Rank()
+	})
+
+	return warnings, nil
+}
+
+func hasBlockingInteraction(warnings []models.InteractionWarning) bool {
+	for _, w := range warnings {
+		if w.BlocksCheckout {
+			return true
+		}
+	}
+	return false
+}
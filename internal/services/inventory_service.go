@@ -0,0 +1,361 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrWriteOffNotFound = errors.New("акт списания не найден")
+var ErrWriteOffReasonRequired = errors.New("поле reason не должно быть пустым")
+var ErrInvalidExpiringDays = errors.New("параметр days должен быть от 1 до " + strconv.Itoa(MaxExpiringDays))
+var ErrInvalidMovementType = errors.New("допустимые типы движения: receipt, return, adjustment, reservation")
+var ErrBatchRequired = repository.ErrBatchRequired
+var ErrBatchNotFound = errors.New("партия не найдена")
+var ErrInvalidSalesPeriod = errors.New("параметр days должен быть от 1 до " + strconv.Itoa(MaxSalesPeriodDays))
+var ErrInvalidCoverDays = errors.New("параметр cover_days должен быть от 1 до " + strconv.Itoa(MaxSalesPeriodDays))
+
+const (
+	DefaultExpiringDays = 30
+	MaxExpiringDays     = 365
+
+	DefaultSalesPeriodDays = 30
+	DefaultCoverDays       = 14
+	MaxSalesPeriodDays     = 365
+)
+
+type InventoryService interface {
+	ListExpiring(days int) ([]models.Batch, error)
+
+	BlockExpiredBatches() (int, error)
+
+	WriteOffExpired(req models.WriteOffCreateRequest) (*models.WriteOff, error)
+
+	GetWriteOff(id uint) (*models.WriteOff, error)
+
+	WriteOffReportCSV(w io.Writer, writeOff *models.WriteOff) error
+
+	AdjustStock(medicineID uint, req models.StockAdjustmentRequest) (*models.StockMovement, error)
+
+	GetStockHistory(medicineID uint, limit, offset int) (*models.StockHistoryResponse, error)
+
+	Reconcile(onlyMismatched bool) ([]models.StockReconciliation, error)
+
+	ListLowStock() ([]models.LowStockAlert, error)
+
+	PendingLowStockAlerts() ([]models.LowStockAlert, error)
+
+	MarkLowStockAlerted(medicineID uint) error
+
+	ReorderSuggestions(days, coverDays int) ([]models.ReorderSuggestion, error)
+}
+
+type inventoryService struct {
+	batches   repository.BatchRepository
+	writeOffs repository.WriteOffRepository
+	movements repository.StockMovementRepository
+	medicines repository.MedicineRepository
+	alerts    repository.LowStockAlertRepository
+}
+
+func NewInventoryService(
+	batches repository.BatchRepository,
+	writeOffs repository.WriteOffRepository,
+	movements repository.StockMovementRepository,
+	medicines repository.MedicineRepository,
+	alerts repository.LowStockAlertRepository,
+) InventoryService {
+	return &inventoryService{
+		batches:   batches,
+		writeOffs: writeOffs,
+		movements: movements,
+		medicines: medicines,
+		alerts:    alerts,
+	}
+}
+
+func (s *inventoryService) ListExpiring(days int) ([]models.Batch, error) {
+	if days <= 0 || days > MaxExpiringDays {
+		return nil, ErrInvalidExpiringDays
+	}
+
+	now := time.Now()
+	return s.batches.ListExpiring(now, now.AddDate(0, 0, days))
+}
+
+func (s *inventoryService) BlockExpiredBatches() (int, error) {
+	medicineIDs, err := s.batches.BlockExpired(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return len(medicineIDs), nil
+}
+
+func (s *inventoryService) WriteOffExpired(req models.WriteOffCreateRequest) (*models.WriteOff, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrWriteOffReasonRequired
+	}
+
+	writeOff, err := s.writeOffs.CreateFromExpired(reason, req.Actor, req.MedicineID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return writeOff, nil
+}
+
+func (s *inventoryService) GetWriteOff(id uint) (*models.WriteOff, error) {
+	writeOff, err := s.writeOffs.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWriteOffNotFound
+		}
+		return nil, err
+	}
+	return writeOff, nil
+}
+
+func (s *inventoryService) WriteOffReportCSV(w io.Writer, writeOff *models.WriteOff) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"write_off_id", "date", "reason", "medicine_id", "medicine_name", "batch_id", "lot_number", "expiry_date", "quantity"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, item := range writeOff.Items {
+		record := []string{
+			strconv.FormatUint(uint64(writeOff.ID), 10),
+			writeOff.CreatedAt.Format(time.DateOnly),
+			writeOff.Reason,
+			strconv.FormatUint(uint64(item.MedicineID), 10),
+			item.MedicineName,
+			strconv.FormatUint(uint64(item.BatchID), 10),
+			item.LotNumber,
+			item.ExpiryDate.Format(time.DateOnly),
+			strconv.Itoa(item.Quantity),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *inventoryService) AdjustStock(medicineID uint, req models.StockAdjustmentRequest) (*models.StockMovement, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	switch req.Type {
+	case models.MovementReceipt, models.MovementReturn:
+		if req.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+	case models.MovementAdjustment, models.MovementReservation:
+		if req.Quantity == 0 {
+			return nil, errors.New("количество не должно быть равно 0")
+		}
+	default:
+		return nil, ErrInvalidMovementType
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("поле reason не должно быть пустым")
+	}
+
+	movement, err := s.movements.Adjust(medicineID, req.BatchID, req.Quantity, req.Type, strings.TrimSpace(req.Actor), reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInsufficientStock):
+			return nil, ErrOutOfStock
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrBatchNotFound
+		}
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (s *inventoryService) GetStockHistory(medicineID uint, limit, offset int) (*models.StockHistoryResponse, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultMedicinePageLimit
+	}
+	if limit > MaxMedicinePageLimit {
+		limit = MaxMedicinePageLimit
+	}
+
+	movements, total, err := s.movements.ListByMedicineID(medicineID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StockHistoryResponse{Items: movements, Total: total}, nil
+}
+
+func (s *inventoryService) Reconcile(onlyMismatched bool) ([]models.StockReconciliation, error) {
+	rows, err := s.movements.Reconcile()
+	if err != nil {
+		return nil, err
+	}
+
+	if !onlyMismatched {
+		return rows, nil
+	}
+
+	mismatched := []models.StockReconciliation{}
+	for _, row := range rows {
+		if row.Difference != 0 {
+			mismatched = append(mismatched, row)
+		}
+	}
+	return mismatched, nil
+}
+
+func (s *inventoryService) ensureMedicineExists(medicineID uint) error {
+	if _, err := s.medicines.GetByID(medicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMedicineNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *inventoryService) ListLowStock() ([]models.LowStockAlert, error) {
+	medicines, err := s.medicines.ListBelowReorderPoint()
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]models.LowStockAlert, 0, len(medicines))
+	for _, medicine := range medicines {
+		alerts = append(alerts, models.LowStockAlert{
+			MedicineID:    medicine.ID,
+			Name:          medicine.Name,
+			StockQuantity: medicine.StockQuantity,
+			ReorderPoint:  medicine.ReorderPoint,
+			TargetStock:   medicine.TargetStock,
+		})
+	}
+	return alerts, nil
+}
+
+func (s *inventoryService) PendingLowStockAlerts() ([]models.LowStockAlert, error) {
+	alerts, err := s.ListLowStock()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make([]uint, 0, len(alerts))
+	for _, alert := range alerts {
+		current = append(current, alert.MedicineID)
+	}
+
+	if err := s.alerts.ClearExcept(current); err != nil {
+		return nil, err
+	}
+
+	alerted, err := s.alerts.ListAlerted()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint]bool, len(alerted))
+	for _, id := range alerted {
+		seen[id] = true
+	}
+
+	pending := make([]models.LowStockAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		if !seen[alert.MedicineID] {
+			pending = append(pending, alert)
+		}
+	}
+	return pending, nil
+}
+
+func (s *inventoryService) MarkLowStockAlerted(medicineID uint) error {
+	return s.alerts.MarkAlerted(medicineID, time.Now())
+}
+
+func (s *inventoryService) ReorderSuggestions(days, coverDays int) ([]models.ReorderSuggestion, error) {
+	if days <= 0 || days > MaxSalesPeriodDays {
+		return nil, ErrInvalidSalesPeriod
+	}
+
+	if coverDays <= 0 || coverDays > MaxSalesPeriodDays {
+		return nil, ErrInvalidCoverDays
+	}
+
+	rows, err := s.medicines.ListSalesSince(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []models.ReorderSuggestion{}
+	for _, row := range rows {
+		velocity := float64(row.Sold) / float64(days)
+		demand := int(math.Ceil(velocity * float64(coverDays)))
+
+		belowReorderPoint := row.ReorderPoint > 0 && row.StockQuantity <= row.ReorderPoint
+		if !belowReorderPoint && row.StockQuantity >= demand {
+			continue
+		}
+
+		target := max(row.TargetStock, row.ReorderPoint, demand)
+		if target <= row.StockQuantity {
+			continue
+		}
+
+		suggestion := models.ReorderSuggestion{
+			MedicineID:        row.MedicineID,
+			Name:              row.Name,
+			StockQuantity:     row.StockQuantity,
+			ReorderPoint:      row.ReorderPoint,
+			TargetStock:       row.TargetStock,
+			SoldInPeriod:      row.Sold,
+			DailyVelocity:     math.Round(velocity*100) / 100,
+			SuggestedQuantity: target - row.StockQuantity,
+		}
+		if velocity > 0 {
+			daysLeft := math.Round(float64(row.StockQuantity)/velocity*10) / 10
+			suggestion.DaysOfStockLeft = &daysLeft
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i].DaysOfStockLeft, suggestions[j].DaysOfStockLeft
+		switch {
+		case a != nil && b != nil:
+			return *a < *b
+		case a != nil:
+			return true
+		case b != nil:
+			return false
+		}
+		return suggestions[i].StockQuantity < suggestions[j].StockQuantity
+	})
+
+	return suggestions, nil
+}
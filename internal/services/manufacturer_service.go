@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrManufacturerNotFound = errors.New("производитель не найден")
+var ErrManufacturerInUse = errors.New("у производителя есть лекарства, удаление невозможно")
+var ErrManufacturerNameTaken = errors.New("название или синоним уже используется другим производителем")
+
+type ManufacturerService interface {
+	CreateManufacturer(req models.ManufacturerCreateRequest) (*models.Manufacturer, error)
+
+	GetManufacturerByID(id uint) (*models.Manufacturer, error)
+
+	GetAllManufacturers(query string) ([]models.Manufacturer, error)
+
+	UpdateManufacturer(id uint, req models.ManufacturerUpdateRequest) (*models.Manufacturer, error)
+
+	DeleteManufacturer(id uint) error
+
+	MergeManufacturers(id uint, req models.ManufacturerMergeRequest) (*models.Manufacturer, error)
+}
+
+type manufacturerService struct {
+	manufacturers repository.ManufacturerRepository
+}
+
+func NewManufacturerService(manufacturers repository.ManufacturerRepository) ManufacturerService {
+	return &manufacturerService{manufacturers: manufacturers}
+}
+
+func (s *manufacturerService) CreateManufacturer(req models.ManufacturerCreateRequest) (*models.Manufacturer, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("поле name не должно быть пустым")
+	}
+
+	aliases, err := s.prepareNames(0, name, req.Aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	manufacturer := &models.Manufacturer{
+		Name:    name,
+		Country: strings.TrimSpace(req.Country),
+	}
+	for _, alias := range aliases {
+		manufacturer.Aliases = append(manufacturer.Aliases, models.ManufacturerAlias{Name: alias})
+	}
+
+	if err := s.manufacturers.Create(manufacturer); err != nil {
+		return nil, err
+	}
+
+	return manufacturer, nil
+}
+
+func (s *manufacturerService) GetManufacturerByID(id uint) (*models.Manufacturer, error) {
+	manufacturer, err := s.manufacturers.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrManufacturerNotFound
+		}
+		return nil, err
+	}
+
+	return manufacturer, nil
+}
+
+func (s *manufacturerService) GetAllManufacturers(query string) ([]models.Manufacturer, error) {
+	return s.manufacturers.GetAll(strings.TrimSpace(query))
+}
+
+func (s *manufacturerService) UpdateManufacturer(id uint, req models.ManufacturerUpdateRequest) (*models.Manufacturer, error) {
+	manufacturer, err := s.GetManufacturerByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("поле name не должно быть пустым")
+		}
+		manufacturer.Name = name
+	}
+
+	if req.Country != nil {
+		manufacturer.Country = strings.TrimSpace(*req.Country)
+	}
+
+	var aliases []string
+	if req.Aliases != nil {
+		aliases = *req.Aliases
+	} else {
+		for _, alias := range manufacturer.Aliases {
+			aliases = append(aliases, alias.Name)
+		}
+	}
+
+	aliases, err = s.prepareNames(id, manufacturer.Name, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Aliases == nil {
+		aliases = nil
+	}
+
+	if err := s.manufacturers.Update(manufacturer, aliases); err != nil {
+		return nil, err
+	}
+
+	return s.GetManufacturerByID(id)
+}
+
+func (s *manufacturerService) DeleteManufacturer(id uint) error {
+	if _, err := s.GetManufacturerByID(id); err != nil {
+		return err
+	}
+
+	count, err := s.manufacturers.CountMedicines(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrManufacturerInUse
+	}
+
+	return s.manufacturers.Delete(id)
+}
+
+func (s *manufacturerService) MergeManufacturers(id uint, req models.ManufacturerMergeRequest) (*models.Manufacturer, error) {
+	if len(req.SourceIDs) == 0 {
+		return nil, errors.New("поле source_ids не должно быть пустым")
+	}
+
+	target, err := s.GetManufacturerByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == id {
+			return nil, errors.New("производитель не может быть объединён сам с собой")
+		}
+		if _, err := s.GetManufacturerByID(sourceID); err != nil {
+			if errors.Is(err, ErrManufacturerNotFound) {
+				return nil, fmt.Errorf("%w: id %d", ErrManufacturerNotFound, sourceID)
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.manufacturers.Merge(target, req.SourceIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetManufacturerByID(id)
+}
+
+func (s *manufacturerService) prepareNames(id uint, name string, rawAliases []string) ([]string, error) {
+	seen := map[string]bool{strings.ToLower(name): true}
+	aliases := make([]string, 0, len(rawAliases))
+
+	for _, raw := range rawAliases {
+		alias := strings.TrimSpace(raw)
+		if alias == "" || seen[strings.ToLower(alias)] {
+			continue
+		}
+		seen[strings.ToLower(alias)] = true
+		aliases = append(aliases, alias)
+	}
+
+	for _, candidate := range append([]string{name}, aliases...) {
+		owner, err := s.manufacturers.FindByName(candidate)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if owner.ID != id {
+			return nil, fmt.Errorf("%w: %s", ErrManufacturerNameTaken, candidate)
+		}
+	}
+
+	return aliases, nil
+}
+
+func resolveManufacturer(manufacturers repository.ManufacturerRepository, id *uint, name string) (*models.Manufacturer, error) {
+	if id != nil {
+		manufacturer, err := manufacturers.GetByID(*id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrManufacturerNotFound
+			}
+			return nil, err
+		}
+		return manufacturer, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+
+	manufacturer, err := manufacturers.FindByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %q", ErrManufacturerNotFound, name)
+		}
+		return nil, err
+	}
+
+	return manufacturer, nil
+}
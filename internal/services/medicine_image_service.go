@@ -0,0 +1,342 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/imaging"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"github.com/kuduzow/team-4-pharmacy/internal/storage"
+	"gorm.io/gorm"
+)
+
+var ErrImageNotFound = errors.New("изображение не найдено")
+var ErrImageTooLarge = errors.New("размер изображения не должен превышать 5 МБ")
+var ErrUnsupportedImageType = errors.New("поддерживаются только изображения JPEG, PNG и GIF")
+var ErrInvalidImage = errors.New("не удалось прочитать изображение")
+var ErrInvalidImageOrder = errors.New("в image_ids должны быть перечислены все изображения лекарства ровно по одному разу")
+
+const (
+	MaxImageSize      = 5 << 20
+	MaxImageDimension = 4000
+	thumbnailQuality  = 85
+)
+
+var ThumbnailSizes = map[string]int{
+	"small":  150,
+	"medium": 400,
+	"large":  800,
+}
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type MedicineImageService interface {
+	UploadImage(medicineID uint, r io.Reader, primary bool) (*models.MedicineImage, error)
+
+	ListImages(medicineID uint) ([]models.MedicineImage, error)
+
+	ReorderImages(medicineID uint, req models.MedicineImageOrderRequest) ([]models.MedicineImage, error)
+
+	SetPrimaryImage(medicineID, imageID uint) ([]models.MedicineImage, error)
+
+	DeleteImage(medicineID, imageID uint) error
+
+	AttachImages(medicines []models.Medicine) error
+
+	OpenMedia(key string) (io.ReadCloser, string, error)
+}
+
+type medicineImageService struct {
+	images    repository.MedicineImageRepository
+	medicines repository.MedicineRepository
+	storage   storage.Storage
+}
+
+func NewMedicineImageService(
+	images repository.MedicineImageRepository,
+	medicines repository.MedicineRepository,
+	storage storage.Storage,
+) MedicineImageService {
+	return &medicineImageService{
+		images:    images,
+		medicines: medicines,
+		storage:   storage,
+	}
+}
+
+func (s *medicineImageService) UploadImage(medicineID uint, r io.Reader, primary bool) (*models.MedicineImage, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageSize {
+		return nil, ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImageType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if config.Width > MaxImageDimension || config.Height > MaxImageDimension {
+		return nil, fmt.Errorf("размер изображения не должен превышать %dx%d пикселей", MaxImageDimension, MaxImageDimension)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("medicines/%d/%s%s", medicineID, name, ext)
+
+	saved := []string{}
+	cleanup := func() {
+		for _, savedKey := range saved {
+			_ = s.storage.Delete(savedKey)
+		}
+	}
+
+	if err := s.storage.Save(key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	saved = append(saved, key)
+
+	sizeNames := make([]string, 0, len(ThumbnailSizes))
+	for sizeName := range ThumbnailSizes {
+		sizeNames = append(sizeNames, sizeName)
+	}
+	sort.Slice(sizeNames, func(i, j int) bool {
+		return ThumbnailSizes[sizeNames[i]] > ThumbnailSizes[sizeNames[j]]
+	})
+
+	base := imaging.Flatten(imaging.Fit(decoded, ThumbnailSizes[sizeNames[0]]), color.White)
+	for _, sizeName := range sizeNames {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, imaging.Fit(base, ThumbnailSizes[sizeName]), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		thumbKey := thumbnailKey(key, sizeName)
+		if err := s.storage.Save(thumbKey, &buf); err != nil {
+			cleanup()
+			return nil, err
+		}
+		saved = append(saved, thumbKey)
+	}
+
+	medicineImage := &models.MedicineImage{
+		MedicineID:  medicineID,
+		IsPrimary:   primary,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       config.Width,
+		Height:      config.Height,
+		StorageKey:  key,
+	}
+
+	if err := s.images.Create(medicineImage); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	s.fillURLs(medicineImage)
+	return medicineImage, nil
+}
+
+func (s *medicineImageService) ListImages(medicineID uint) ([]models.MedicineImage, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.images.ListByMedicineID(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range images {
+		s.fillURLs(&images[i])
+	}
+	return images, nil
+}
+
+func (s *medicineImageService) ReorderImages(medicineID uint, req models.MedicineImageOrderRequest) ([]models.MedicineImage, error) {
+	images, err := s.ListImages(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.ImageIDs) != len(images) {
+		return nil, ErrInvalidImageOrder
+	}
+
+	existing := make(map[uint]bool, len(images))
+	for _, medicineImage := range images {
+		existing[medicineImage.ID] = true
+	}
+	for _, id := range req.ImageIDs {
+		if !existing[id] {
+			return nil, ErrInvalidImageOrder
+		}
+		delete(existing, id)
+	}
+
+	if err := s.images.Reorder(medicineID, req.ImageIDs); err != nil {
+		return nil, err
+	}
+
+	return s.ListImages(medicineID)
+}
+
+func (s *medicineImageService) SetPrimaryImage(medicineID, imageID uint) ([]models.MedicineImage, error) {
+	if _, err := s.getImage(medicineID, imageID); err != nil {
+		return nil, err
+	}
+
+	if err := s.images.SetPrimary(medicineID, imageID); err != nil {
+		return nil, err
+	}
+
+	return s.ListImages(medicineID)
+}
+
+func (s *medicineImageService) DeleteImage(medicineID, imageID uint) error {
+	medicineImage, err := s.getImage(medicineID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.images.Delete(medicineImage); err != nil {
+		return err
+	}
+
+	_ = s.storage.Delete(medicineImage.StorageKey)
+	for sizeName := range ThumbnailSizes {
+		_ = s.storage.Delete(thumbnailKey(medicineImage.StorageKey, sizeName))
+	}
+
+	return nil
+}
+
+func (s *medicineImageService) AttachImages(medicines []models.Medicine) error {
+	if len(medicines) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(medicines))
+	for i, medicine := range medicines {
+		ids[i] = medicine.ID
+	}
+
+	images, err := s.images.ListByMedicineIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	byMedicine := make(map[uint][]models.MedicineImage, len(medicines))
+	for i := range images {
+		s.fillURLs(&images[i])
+		byMedicine[images[i].MedicineID] = append(byMedicine[images[i].MedicineID], images[i])
+	}
+
+	for i := range medicines {
+		medicines[i].Images = byMedicine[medicines[i].ID]
+	}
+
+	return nil
+}
+
+func (s *medicineImageService) OpenMedia(key string) (io.ReadCloser, string, error) {
+	file, err := s.storage.Open(strings.TrimPrefix(key, "/"))
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return nil, "", ErrImageNotFound
+		}
+		return nil, "", err
+	}
+
+	contentType := "application/octet-stream"
+	for mimeType, ext := range imageExtensions {
+		if path.Ext(key) == ext {
+			contentType = mimeType
+		}
+	}
+
+	return file, contentType, nil
+}
+
+func (s *medicineImageService) fillURLs(medicineImage *models.MedicineImage) {
+	medicineImage.URL = s.storage.URL(medicineImage.StorageKey)
+	medicineImage.Thumbnails = make(map[string]string, len(ThumbnailSizes))
+	for sizeName := range ThumbnailSizes {
+		medicineImage.Thumbnails[sizeName] = s.storage.URL(thumbnailKey(medicineImage.StorageKey, sizeName))
+	}
+}
+
+func (s *medicineImageService) getImage(medicineID, imageID uint) (*models.MedicineImage, error) {
+	if err := s.ensureMedicineExists(medicineID); err != nil {
+		return nil, err
+	}
+
+	medicineImage, err := s.images.GetByID(medicineID, imageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	return medicineImage, nil
+}
+
+func (s *medicineImageService) ensureMedicineExists(medicineID uint) error {
+	if _, err := s.medicines.GetByID(medicineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMedicineNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func thumbnailKey(key, sizeName string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_" + sizeName + ".jpg"
+}
+
+func randomName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -1,8 +1,13 @@
 package services
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
 	"github.com/kuduzow/team-4-pharmacy/internal/repository"
@@ -10,61 +15,199 @@ import (
 )
 
 var ErrMedicineNotFound = errors.New("лекарство не найдено")
+var ErrSubcategoryMismatch = errors.New("подкатегория не принадлежит указанной категории")
+var ErrStockManagedByBatches = errors.New("остаток лекарства рассчитывается по партиям и не может быть изменён напрямую")
+var ErrInvalidPageToken = errors.New("некорректный page_token")
+var ErrSuggestQueryTooShort = errors.New("запрос для подсказок должен содержать минимум 2 символа")
+var ErrSharedProductField = errors.New("описание и производитель задаются на уровне товара: используйте PATCH /products/:id")
+var ErrInvalidPackSize = errors.New("размер упаковки не должен быть отрицательным")
+var ErrInvalidReorderLevels = errors.New("некорректные точка заказа и целевой остаток")
+
+const (
+	DefaultMedicinePageLimit = 20
+	MaxMedicinePageLimit     = 100
+
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 20
+)
 
 type MedicineService interface {
 	CreateMedicine(req models.MedicineCreateRequest) (*models.Medicine, error)
 
-	GetMedicineByID(id uint) (*models.Medicine, error)
+	GetMedicineByID(id uint, location *repository.StockLocation) (*models.Medicine, error)
+
+	GetMedicineByBarcode(code string, location *repository.StockLocation) (*models.Medicine, error)
 
 	UpdateMedicine(id uint, req models.MedicineUpdateRequest) (*models.Medicine, error)
 
 	DeleteMedicine(id uint) error
 
-	ListMedicines(filter repository.MedicineFilter) ([]models.Medicine, error)
+	ListMedicines(filter repository.MedicineFilter) (*models.MedicineListResponse, error)
+
+	SuggestMedicines(query string, limit int) ([]models.MedicineSuggestion, error)
+
+	GetMedicineFacets(filter repository.MedicineFilter) (*models.MedicineFacets, error)
+
+	ValidateCreateMedicine(req models.MedicineCreateRequest) error
+
+	ImportMedicine(req models.MedicineCreateRequest, updateStock bool) (bool, error)
 }
 
 type medicineService struct {
-	medicines  repository.MedicineRepository
-	categories repository.CategoryRepository
+	medicines     repository.MedicineRepository
+	categories    repository.CategoryRepository
+	batches       repository.BatchRepository
+	images        MedicineImageService
+	products      repository.ProductRepository
+	manufacturers repository.ManufacturerRepository
 }
 
 func NewMedicineService(
 	medicines repository.MedicineRepository,
 	categories repository.CategoryRepository,
+	batches repository.BatchRepository,
+	images MedicineImageService,
+	products repository.ProductRepository,
+	manufacturers repository.ManufacturerRepository,
 ) MedicineService {
 	return &medicineService{
-		medicines:  medicines,
-		categories: categories,
+		medicines:     medicines,
+		categories:    categories,
+		batches:       batches,
+		images:        images,
+		products:      products,
+		manufacturers: manufacturers,
 	}
 }
 
 func (s *medicineService) CreateMedicine(req models.MedicineCreateRequest) (*models.Medicine, error) {
+	if req.ProductID != nil {
+		product, err := s.products.GetByID(*req.ProductID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrProductNotFound
+			}
+			return nil, err
+		}
+		req.Description = product.Description
+		req.Manufacturer = product.Manufacturer
+		req.ManufacturerID = product.ManufacturerID
+		req.CategoryID = product.CategoryID
+		req.SubcategoryID = product.SubcategoryID
+	}
+
 	if err := s.ValidateCreateMedicine(req); err != nil {
 		return nil, err
 	}
 
-	inStock := req.StockQuantity > 0
+	codes, err := s.prepareBarcodes(req.Barcodes, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyManufacturer(&req); err != nil {
+		return nil, err
+	}
+
 	medicine := &models.Medicine{
+		ProductID:            req.ProductID,
+		SKU:                  optionalString(req.SKU),
 		Name:                 req.Name,
 		Description:          req.Description,
 		Price:                req.Price,
-		InStock:              inStock,
-		StockQuantity:        req.StockQuantity,
 		CategoryID:           req.CategoryID,
 		SubcategoryID:        req.SubcategoryID,
 		Manufacturer:         req.Manufacturer,
+		ManufacturerID:       req.ManufacturerID,
 		PrescriptionRequired: req.PrescriptionRequired,
-		AvgRating:            req.AvgRating,
+		ReorderPoint:         req.ReorderPoint,
+		TargetStock:          req.TargetStock,
+		Strength:             strings.TrimSpace(req.Strength),
+		DosageForm:           strings.TrimSpace(req.DosageForm),
+		PackSize:             req.PackSize,
+	}
+
+	for _, code := range codes {
+		medicine.Barcodes = append(medicine.Barcodes, models.MedicineBarcode{Code: code})
+	}
+
+	changes := repository.MedicineChanges{
+		Actor:       req.Actor,
+		Stock:       &req.StockQuantity,
+		StockReason: "начальный остаток",
+		PriceSource: models.PriceSourceInitial,
 	}
 
-	if err := s.medicines.Create(medicine); err != nil {
+	if err := s.medicines.Create(medicine, changes); err != nil {
 		return nil, err
 	}
 
 	return medicine, nil
 }
 
-func (s *medicineService) GetMedicineByID(id uint) (*models.Medicine, error) {
+func (s *medicineService) ImportMedicine(req models.MedicineCreateRequest, updateStock bool) (bool, error) {
+	req.SKU = strings.TrimSpace(req.SKU)
+	if req.SKU == "" {
+		return false, errors.New("поле sku не должно быть пустым")
+	}
+
+	medicine, err := s.medicines.GetBySKU(req.SKU)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+		if _, err := s.CreateMedicine(req); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := s.ValidateCreateMedicine(req); err != nil {
+		return false, err
+	}
+
+	if err := s.applyManufacturer(&req); err != nil {
+		return false, err
+	}
+
+	stockChanged := updateStock && req.StockQuantity != medicine.StockQuantity
+	if stockChanged {
+		count, err := s.batches.CountByMedicineID(medicine.ID)
+		if err != nil {
+			return false, err
+		}
+		stockChanged = count == 0
+	}
+
+	medicine.Name = req.Name
+	medicine.Description = req.Description
+	medicine.CategoryID = req.CategoryID
+	medicine.SubcategoryID = req.SubcategoryID
+	medicine.Manufacturer = req.Manufacturer
+	medicine.ManufacturerID = req.ManufacturerID
+	medicine.PrescriptionRequired = req.PrescriptionRequired
+
+	changes := repository.MedicineChanges{
+		Actor:       req.Actor,
+		StockReason: "импорт каталога",
+		Price:       &req.Price,
+		PriceSource: models.PriceSourceImport,
+		PriceReason: "импорт каталога",
+	}
+	if stockChanged {
+		changes.Stock = &req.StockQuantity
+	}
+
+	if err := s.updateMedicine(medicine, changes); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+func (s *medicineService) GetMedicineByID(id uint, location *repository.StockLocation) (*models.Medicine, error) {
+	normalizeStockLocation(location)
+
 	medicine, err := s.medicines.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -73,7 +216,37 @@ func (s *medicineService) GetMedicineByID(id uint) (*models.Medicine, error) {
 		return nil, err
 	}
 
-	return medicine, nil
+	if location != nil {
+		quantity, err := s.medicines.LocalStock(id, *location)
+		if err != nil {
+			return nil, err
+		}
+		medicine.LocalStock = &quantity
+	}
+
+	medicines := []models.Medicine{*medicine}
+	if err := s.images.AttachImages(medicines); err != nil {
+		return nil, err
+	}
+
+	return &medicines[0], nil
+}
+
+func (s *medicineService) GetMedicineByBarcode(code string, location *repository.StockLocation) (*models.Medicine, error) {
+	gtin, err := NormalizeGTIN(code)
+	if err != nil {
+		return nil, err
+	}
+
+	medicine, err := s.medicines.GetByBarcode(gtin)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMedicineNotFound
+		}
+		return nil, err
+	}
+
+	return s.GetMedicineByID(medicine.ID, location)
 }
 
 func (s *medicineService) UpdateMedicine(id uint, req models.MedicineUpdateRequest) (*models.Medicine, error) {
@@ -86,11 +259,45 @@ func (s *medicineService) UpdateMedicine(id uint, req models.MedicineUpdateReque
 		return nil, err
 	}
 
+	if req.StockQuantity != nil {
+		count, err := s.batches.CountByMedicineID(id)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrStockManagedByBatches
+		}
+	}
+
+	var codes []string
+	if req.Barcodes != nil {
+		if codes, err = s.prepareBarcodes(*req.Barcodes, id); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.ApplyMedicineUpdate(medicine, req); err != nil {
 		return nil, err
 	}
 
-	if err := s.medicines.Update(medicine); err != nil {
+	reason := strings.TrimSpace(req.StockReason)
+	if reason == "" {
+		reason = "ручная корректировка остатка"
+	}
+
+	changes := repository.MedicineChanges{
+		Actor:       req.Actor,
+		Stock:       req.StockQuantity,
+		StockReason: reason,
+		Price:       req.Price,
+		PriceSource: models.PriceSourceManual,
+		PriceReason: req.PriceReason,
+	}
+	if req.Barcodes != nil {
+		changes.Barcodes = &codes
+	}
+
+	if err := s.updateMedicine(medicine, changes); err != nil {
 		return nil, err
 	}
 
@@ -110,8 +317,130 @@ func (s *medicineService) DeleteMedicine(id uint) error {
 	return s.medicines.Delete(id)
 }
 
-func (s *medicineService) ListMedicines(filter repository.MedicineFilter) ([]models.Medicine, error) {
-	return s.medicines.List(filter)
+func (s *medicineService) ListMedicines(filter repository.MedicineFilter) (*models.MedicineListResponse, error) {
+	normalizeStockLocation(filter.Location)
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultMedicinePageLimit
+	}
+
+	if filter.Limit > MaxMedicinePageLimit {
+		filter.Limit = MaxMedicinePageLimit
+	}
+
+	medicines, total, err := s.medicines.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.images.AttachImages(medicines); err != nil {
+		return nil, err
+	}
+
+	response := &models.MedicineListResponse{
+		Items: medicines,
+		Total: total,
+	}
+
+	if next := filter.Offset + len(medicines); len(medicines) > 0 && int64(next) < total {
+		response.NextPageToken = EncodePageToken(next)
+	}
+
+	return response, nil
+}
+
+func (s *medicineService) SuggestMedicines(query string, limit int) ([]models.MedicineSuggestion, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < 2 {
+		return nil, ErrSuggestQueryTooShort
+	}
+
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+
+	if limit > MaxSuggestLimit {
+		limit = MaxSuggestLimit
+	}
+
+	return s.medicines.Suggest(query, limit)
+}
+
+func (s *medicineService) GetMedicineFacets(filter repository.MedicineFilter) (*models.MedicineFacets, error) {
+	normalizeStockLocation(filter.Location)
+
+	rows, err := s.medicines.Facets(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := &models.MedicineFacets{
+		Categories:           []models.FacetCount{},
+		Subcategories:        []models.FacetCount{},
+		Manufacturers:        []models.FacetCount{},
+		PrescriptionRequired: []models.FacetCount{},
+		InStock:              []models.FacetCount{},
+		PriceBuckets:         []models.PriceBucketCount{},
+	}
+
+	for _, row := range rows {
+		switch row.Facet {
+		case "category":
+			facets.Categories = append(facets.Categories, models.FacetCount{Value: row.CategoryID, Count: row.Count})
+		case "subcategory":
+			facets.Subcategories = append(facets.Subcategories, models.FacetCount{Value: row.SubcategoryID, Count: row.Count})
+		case "manufacturer":
+			facets.Manufacturers = append(facets.Manufacturers, models.FacetCount{Value: row.Manufacturer, Count: row.Count})
+		case "prescription_required":
+			facets.PrescriptionRequired = append(facets.PrescriptionRequired, models.FacetCount{Value: row.PrescriptionRequired, Count: row.Count})
+		case "in_stock":
+			facets.InStock = append(facets.InStock, models.FacetCount{Value: row.InStock, Count: row.Count})
+		case "price_bucket":
+			if row.PriceBucket != nil {
+				facets.PriceBuckets = append(facets.PriceBuckets, priceBucketCount(*row.PriceBucket, row.Count))
+			}
+		}
+	}
+
+	sort.Slice(facets.PriceBuckets, func(i, j int) bool {
+		return facets.PriceBuckets[i].From < facets.PriceBuckets[j].From
+	})
+
+	return facets, nil
+}
+
+func priceBucketCount(bucket int, count int64) models.PriceBucketCount {
+	bounds := repository.MedicinePriceBucketBounds
+	result := models.PriceBucketCount{Count: count}
+
+	if bucket > 0 {
+		result.From = bounds[bucket-1]
+	}
+
+	if bucket < len(bounds) {
+		to := bounds[bucket]
+		result.To = &to
+	}
+
+	return result
+}
+
+func EncodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func DecodePageToken(token string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidPageToken
+	}
+
+	return offset, nil
 }
 
 func (s *medicineService) ApplyMedicineUpdate(medicine *models.Medicine, req models.MedicineUpdateRequest) error {
@@ -135,17 +464,92 @@ func (s *medicineService) ApplyMedicineUpdate(medicine *models.Medicine, req mod
 		if *req.StockQuantity < 0 {
 			return errors.New("количество на складе не должно быть отрицательным")
 		}
-		medicine.StockQuantity = *req.StockQuantity
-		medicine.InStock = *req.StockQuantity > 0
 	}
 
-	if req.InStock != nil {
-		medicine.InStock = *req.InStock
+	if req.ReorderPoint != nil {
+		medicine.ReorderPoint = *req.ReorderPoint
+	}
+
+	if req.TargetStock != nil {
+		medicine.TargetStock = *req.TargetStock
+	}
+
+	if medicine.ProductID != nil && (req.Description != nil || req.ManufacturerID != nil || req.Manufacturer != nil) {
+		return ErrSharedProductField
+	}
+
+	if req.ManufacturerID != nil || req.Manufacturer != nil {
+		var name string
+		if req.Manufacturer != nil {
+			name = *req.Manufacturer
+		}
+
+		manufacturer, err := resolveManufacturer(s.manufacturers, req.ManufacturerID, name)
+		if err != nil {
+			return err
+		}
+
+		medicine.Manufacturer = ""
+		medicine.ManufacturerID = nil
+		if manufacturer != nil {
+			medicine.Manufacturer = manufacturer.Name
+			medicine.ManufacturerID = &manufacturer.ID
+		}
+	}
+
+	if req.Strength != nil {
+		medicine.Strength = strings.TrimSpace(*req.Strength)
+	}
+
+	if req.DosageForm != nil {
+		medicine.DosageForm = strings.TrimSpace(*req.DosageForm)
+	}
+
+	if req.PackSize != nil {
+		if *req.PackSize < 0 {
+			return ErrInvalidPackSize
+		}
+		medicine.PackSize = *req.PackSize
 	}
 
+	return validateReorderLevels(medicine.ReorderPoint, medicine.TargetStock)
+}
+
+func (s *medicineService) applyManufacturer(req *models.MedicineCreateRequest) error {
+	manufacturer, err := resolveManufacturer(s.manufacturers, req.ManufacturerID, req.Manufacturer)
+	if err != nil {
+		return err
+	}
+
+	if manufacturer != nil {
+		req.Manufacturer = manufacturer.Name
+		req.ManufacturerID = &manufacturer.ID
+	}
 	return nil
 }
 
+func (s *medicineService) prepareBarcodes(raw []string, medicineID uint) ([]string, error) {
+	codes, err := normalizeBarcodes(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range codes {
+		owner, err := s.medicines.GetByBarcode(code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if owner.ID != medicineID {
+			return nil, fmt.Errorf("%w: %s", ErrBarcodeTaken, code)
+		}
+	}
+
+	return codes, nil
+}
+
 func (s *medicineService) ValidateCreateMedicine(req models.MedicineCreateRequest) error {
 	if req.CategoryID == 0 {
 		return errors.New("поле category_id должно быть больше 0")
@@ -167,5 +571,63 @@ func (s *medicineService) ValidateCreateMedicine(req models.MedicineCreateReques
 		return errors.New("количество лекарств на складе не должно быть отрицательным")
 	}
 
+	if req.PackSize < 0 {
+		return ErrInvalidPackSize
+	}
+
+	if err := validateReorderLevels(req.ReorderPoint, req.TargetStock); err != nil {
+		return err
+	}
+
+	if _, err := s.categories.GetCategoryByID(req.CategoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	subcategory, err := s.categories.GetSubcategoryByID(req.SubcategoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubcategoryNotFound
+		}
+		return err
+	}
+
+	if subcategory.CategoryID != req.CategoryID {
+		return ErrSubcategoryMismatch
+	}
+
+	return nil
+}
+
+func validateReorderLevels(reorderPoint, targetStock int) error {
+	if reorderPoint < 0 || targetStock < 0 {
+		return fmt.Errorf("%w: значения не должны быть отрицательными", ErrInvalidReorderLevels)
+	}
+
+	if targetStock > 0 && targetStock <= reorderPoint {
+		return fmt.Errorf("%w: целевой остаток должен быть больше точки заказа", ErrInvalidReorderLevels)
+	}
+
 	return nil
 }
+
+func (s *medicineService) updateMedicine(medicine *models.Medicine, changes repository.MedicineChanges) error {
+	err := s.medicines.Update(medicine, changes)
+	switch {
+	case errors.Is(err, repository.ErrBatchRequired):
+		return ErrStockManagedByBatches
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return ErrOutOfStock
+	}
+	return err
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+)
+
+func TestApplyMedicineUpdateRejectsSharedProductFields(t *testing.T) {
+	productID := uint(1)
+	description := "новое описание"
+	manufacturer := "Новый производитель"
+
+	tests := []struct {
+		name string
+		req  models.MedicineUpdateRequest
+	}{
+		{name: "description", req: models.MedicineUpdateRequest{Description: &description}},
+		{name: "manufacturer", req: models.MedicineUpdateRequest{Manufacturer: &manufacturer}},
+		{name: "manufacturer_id", req: models.MedicineUpdateRequest{ManufacturerID: &productID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			medicine := &models.Medicine{ProductID: &productID, Description: "описание", Manufacturer: "Производитель"}
+
+			err := (&medicineService{}).ApplyMedicineUpdate(medicine, tt.req)
+			if !errors.Is(err, ErrSharedProductField) {
+				t.Fatalf("err = %v, want %v", err, ErrSharedProductField)
+			}
+			if medicine.Description != "описание" || medicine.Manufacturer != "Производитель" {
+				t.Errorf("shared fields changed: %q, %q", medicine.Description, medicine.Manufacturer)
+			}
+		})
+	}
+}
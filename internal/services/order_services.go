@@ -22,21 +22,49 @@ type OrderService interface {
 }
 
 type orderService struct {
-	order   repository.OrderRepository
-	payment repository.PaymentRepository
-	user    repository.UserRepository
+	order        repository.OrderRepository
+	payment      repository.PaymentRepository
+	user         repository.UserRepository
+	carts        repository.CartRepository
+	branches     repository.BranchRepository
+	interactions InteractionService
 }
 
-func NewOrderService(order repository.OrderRepository, payment repository.PaymentRepository) OrderService {
+func NewOrderService(
+	order repository.OrderRepository,
+	payment repository.PaymentRepository,
+	carts repository.CartRepository,
+	branches repository.BranchRepository,
+	interactions InteractionService,
+) OrderService {
 	return &orderService{
-		order:   order,
-		payment: payment,
+		order:        order,
+		payment:      payment,
+		carts:        carts,
+		branches:     branches,
+		interactions: interactions,
 	}
 }
 func (c *orderService) CreateOrder(req models.OrderCreate) (*models.Order, error) {
 	if err := c.validateOrderCreate(req); err != nil {
 		return nil, err
 	}
+
+	cart, err := c.carts.GetByUserID(req.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if cart != nil {
+		warnings, err := c.interactions.CheckItems(cart.Items)
+		if err != nil {
+			return nil, err
+		}
+		if hasBlockingInteraction(warnings) && !req.AcknowledgeInteractions {
+			return nil, &InteractionsNotAcknowledgedError{Warnings: warnings}
+		}
+	}
+
 	order := &models.Order{
 		UserID:          req.UserID,
 		OrderStatus:     req.OrderStatus,
@@ -45,8 +73,30 @@ func (c *orderService) CreateOrder(req models.OrderCreate) (*models.Order, error
 		FinalPrice:      req.FinalPrice,
 		DeliveryAddress: req.DeliveryAddress,
 		Comment:         req.Comment,
+		BranchID:        req.BranchID,
+	}
+	if cart == nil || len(cart.Items) == 0 {
+		if err := c.order.Create(order); err != nil {
+			return nil, err
+		}
+		return order, nil
+	}
+
+	if req.BranchID == nil {
+		return nil, ErrBranchRequired
 	}
-	if err := c.order.Create(order); err != nil {
+
+	if _, err := c.branches.GetByID(*req.BranchID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBranchNotFound
+		}
+		return nil, err
+	}
+
+	if err := c.order.CreateFromCart(order, cart); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			return nil, ErrOutOfStock
+		}
 		return nil, err
 	}
 	return order, nil
@@ -69,6 +119,11 @@ func (c *orderService) UpdateOrder(id uint, req models.OrderUpdate) (*models.Ord
 		}
 		return nil, err
 	}
+	if order.OrderStatus == models.Canceled && req.OrderStatus != nil && *req.OrderStatus != models.Canceled {
+		return nil, ErrInvalidStatusChange
+	}
+
+	cancel := req.OrderStatus != nil && *req.OrderStatus == models.Canceled
 	if req.OrderStatus != nil {
 		order.OrderStatus = *req.OrderStatus
 	}
@@ -79,7 +134,12 @@ func (c *orderService) UpdateOrder(id uint, req models.OrderUpdate) (*models.Ord
 		order.Comment = *req.Comment
 	}
 
-	if err := c.order.Update(order); err != nil {
+	if cancel {
+		err = c.order.Cancel(order)
+	} else {
+		err = c.order.Update(order)
+	}
+	if err != nil {
 		return nil, err
 	}
 
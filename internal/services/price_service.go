@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrScheduledPriceNotFound = errors.New("запланированная цена не найдена")
+var ErrScheduledPriceApplied = errors.New("запланированная цена уже применена")
+
+const PriceDropWindow = 30 * 24 * time.Hour
+
+type PriceService interface {
+	SchedulePrice(medicineID uint, req models.ScheduledPriceRequest) (*models.ScheduledPrice, error)
+
+	CancelScheduledPrice(medicineID, id uint) error
+
+	GetPriceHistory(medicineID uint, limit, offset int) (*models.PriceHistoryResponse, error)
+
+	ApplyScheduledPrices() (int, error)
+}
+
+type priceService struct {
+	prices    repository.PriceRepository
+	medicines repository.MedicineRepository
+}
+
+func NewPriceService(prices repository.PriceRepository, medicines repository.MedicineRepository) PriceService {
+	return &priceService{
+		prices:    prices,
+		medicines: medicines,
+	}
+}
+
+func (s *priceService) SchedulePrice(medicineID uint, req models.ScheduledPriceRequest) (*models.ScheduledPrice, error) {
+	if _, err := s.getMedicine(medicineID); err != nil {
+		return nil, err
+	}
+
+	if req.Price <= 0 {
+		return nil, errors.New("цена лекарства должна быть больше 0")
+	}
+
+	if !req.EffectiveFrom.After(time.Now()) {
+		return nil, errors.New("дата effective_from должна быть в будущем")
+	}
+
+	price := &models.ScheduledPrice{
+		MedicineID:    medicineID,
+		Price:         req.Price,
+		EffectiveFrom: req.EffectiveFrom,
+		Actor:         strings.TrimSpace(req.Actor),
+		Reason:        strings.TrimSpace(req.Reason),
+	}
+
+	if err := s.prices.CreateScheduled(price); err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+func (s *priceService) CancelScheduledPrice(medicineID, id uint) error {
+	price, err := s.prices.GetScheduled(medicineID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrScheduledPriceNotFound
+		}
+		return err
+	}
+
+	if price.AppliedAt != nil {
+		return ErrScheduledPriceApplied
+	}
+
+	return s.prices.DeleteScheduled(price.ID)
+}
+
+func (s *priceService) GetPriceHistory(medicineID uint, limit, offset int) (*models.PriceHistoryResponse, error) {
+	medicine, err := s.getMedicine(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultMedicinePageLimit
+	}
+	if limit > MaxMedicinePageLimit {
+		limit = MaxMedicinePageLimit
+	}
+
+	changes, total, err := s.prices.ListChanges(medicineID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.prices.ListPendingScheduled(medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.PriceHistoryResponse{
+		MedicineID:   medicineID,
+		CurrentPrice: medicine.Price,
+		Scheduled:    scheduled,
+		Items:        changes,
+		Total:        total,
+	}
+
+	last, err := s.prices.LastChange(medicineID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if last != nil && last.NewPrice < last.OldPrice && last.NewPrice == medicine.Price &&
+		time.Since(last.CreatedAt) <= PriceDropWindow {
+		response.PriceDrop = &models.PriceDrop{
+			PreviousPrice: last.OldPrice,
+			CurrentPrice:  last.NewPrice,
+			Percent:       math.Round((last.OldPrice-last.NewPrice)/last.OldPrice*1000) / 10,
+			DroppedAt:     last.CreatedAt,
+		}
+	}
+
+	return response, nil
+}
+
+func (s *priceService) ApplyScheduledPrices() (int, error) {
+	changes, err := s.prices.ApplyDue(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
+
+func (s *priceService) getMedicine(medicineID uint) (*models.Medicine, error) {
+	medicine, err := s.medicines.GetByID(medicineID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMedicineNotFound
+		}
+		return nil, err
+	}
+	return medicine, nil
+}
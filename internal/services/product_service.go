@@ -0,0 +1,314 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrProductNotFound = errors.New("товар не найден")
+var ErrProductHasVariants = errors.New("у товара есть варианты, удаление невозможно")
+
+type ProductService interface {
+	CreateProduct(req models.ProductCreateRequest) (*models.Product, error)
+
+	GetProductByID(id uint) (*models.Product, error)
+
+	ListProducts(filter repository.MedicineFilter) (*models.ProductListResponse, error)
+
+	UpdateProduct(id uint, req models.ProductUpdateRequest) (*models.Product, error)
+
+	DeleteProduct(id uint) error
+
+	CreateVariant(productID uint, req models.VariantCreateRequest) (*models.Medicine, error)
+
+	AttachVariants(productID uint, req models.AttachVariantsRequest) (*models.Product, error)
+}
+
+type productService struct {
+	products      repository.ProductRepository
+	medicines     repository.MedicineRepository
+	categories    repository.CategoryRepository
+	variants      MedicineService
+	images        MedicineImageService
+	manufacturers repository.ManufacturerRepository
+}
+
+func NewProductService(
+	products repository.ProductRepository,
+	medicines repository.MedicineRepository,
+	categories repository.CategoryRepository,
+	variants MedicineService,
+	images MedicineImageService,
+	manufacturers repository.ManufacturerRepository,
+) ProductService {
+	return &productService{
+		products:      products,
+		medicines:     medicines,
+		categories:    categories,
+		variants:      variants,
+		images:        images,
+		manufacturers: manufacturers,
+	}
+}
+
+func (s *productService) CreateProduct(req models.ProductCreateRequest) (*models.Product, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("поле name не должно быть пустым")
+	}
+
+	if err := s.validateCategories(req.CategoryID, req.SubcategoryID); err != nil {
+		return nil, err
+	}
+
+	manufacturer, err := resolveManufacturer(s.manufacturers, req.ManufacturerID, req.Manufacturer)
+	if err != nil {
+		return nil, err
+	}
+
+	product := &models.Product{
+		Name:          name,
+		Description:   req.Description,
+		CategoryID:    req.CategoryID,
+		SubcategoryID: req.SubcategoryID,
+	}
+	if manufacturer != nil {
+		product.Manufacturer = manufacturer.Name
+		product.ManufacturerID = &manufacturer.ID
+	}
+
+	if err := s.products.Create(product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (s *productService) GetProductByID(id uint) (*models.Product, error) {
+	product, err := s.products.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.images.AttachImages(product.Variants); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (s *productService) ListProducts(filter repository.MedicineFilter) (*models.ProductListResponse, error) {
+	normalizeStockLocation(filter.Location)
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultMedicinePageLimit
+	}
+
+	if filter.Limit > MaxMedicinePageLimit {
+		filter.Limit = MaxMedicinePageLimit
+	}
+
+	products, total, err := s.products.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range products {
+		if err := s.images.AttachImages(products[i].Variants); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &models.ProductListResponse{
+		Items: products,
+		Total: total,
+	}
+
+	if next := filter.Offset + len(products); len(products) > 0 && int64(next) < total {
+		response.NextPageToken = EncodePageToken(next)
+	}
+
+	return response, nil
+}
+
+func (s *productService) UpdateProduct(id uint, req models.ProductUpdateRequest) (*models.Product, error) {
+	product, err := s.GetProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("поле name не должно быть пустым")
+		}
+		product.Name = name
+	}
+
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+
+	if req.ManufacturerID != nil || req.Manufacturer != nil {
+		var name string
+		if req.Manufacturer != nil {
+			name = *req.Manufacturer
+		}
+
+		manufacturer, err := resolveManufacturer(s.manufacturers, req.ManufacturerID, name)
+		if err != nil {
+			return nil, err
+		}
+
+		product.Manufacturer = ""
+		product.ManufacturerID = nil
+		if manufacturer != nil {
+			product.Manufacturer = manufacturer.Name
+			product.ManufacturerID = &manufacturer.ID
+		}
+	}
+
+	if req.CategoryID != nil {
+		product.CategoryID = *req.CategoryID
+	}
+
+	if req.SubcategoryID != nil {
+		product.SubcategoryID = *req.SubcategoryID
+	}
+
+	if req.CategoryID != nil || req.SubcategoryID != nil {
+		if err := s.validateCategories(product.CategoryID, product.SubcategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.products.Update(product); err != nil {
+		return nil, err
+	}
+
+	return s.GetProductByID(id)
+}
+
+func (s *productService) DeleteProduct(id uint) error {
+	if _, err := s.GetProductByID(id); err != nil {
+		return err
+	}
+
+	count, err := s.products.CountVariants(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrProductHasVariants
+	}
+
+	return s.products.Delete(id)
+}
+
+func (s *productService) CreateVariant(productID uint, req models.VariantCreateRequest) (*models.Medicine, error) {
+	product, err := s.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = variantName(product.Name, req)
+	}
+
+	return s.variants.CreateMedicine(models.MedicineCreateRequest{
+		ProductID:            &product.ID,
+		SKU:                  req.SKU,
+		Name:                 name,
+		Price:                req.Price,
+		StockQuantity:        req.StockQuantity,
+		PrescriptionRequired: req.PrescriptionRequired,
+		Strength:             req.Strength,
+		DosageForm:           req.DosageForm,
+		PackSize:             req.PackSize,
+		Actor:                req.Actor,
+	})
+}
+
+func (s *productService) AttachVariants(productID uint, req models.AttachVariantsRequest) (*models.Product, error) {
+	if len(req.MedicineIDs) == 0 {
+		return nil, errors.New("поле medicine_ids не должно быть пустым")
+	}
+
+	product, err := s.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range req.MedicineIDs {
+		if _, err := s.medicines.GetByID(id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: id %d", ErrMedicineNotFound, id)
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.products.AttachVariants(product, req.MedicineIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetProductByID(productID)
+}
+
+func (s *productService) validateCategories(categoryID, subcategoryID uint) error {
+	if categoryID == 0 {
+		return errors.New("поле category_id должно быть больше 0")
+	}
+
+	if subcategoryID == 0 {
+		return errors.New("поле subcategory_id должно быть больше 0")
+	}
+
+	if _, err := s.categories.GetCategoryByID(categoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	subcategory, err := s.categories.GetSubcategoryByID(subcategoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubcategoryNotFound
+		}
+		return err
+	}
+
+	if subcategory.CategoryID != categoryID {
+		return ErrSubcategoryMismatch
+	}
+
+	return nil
+}
+
+func variantName(productName string, req models.VariantCreateRequest) string {
+	parts := []string{productName}
+
+	if strength := strings.TrimSpace(req.Strength); strength != "" {
+		parts = append(parts, strength)
+	}
+
+	if form := strings.TrimSpace(req.DosageForm); form != "" {
+		parts = append(parts, form)
+	}
+
+	if req.PackSize > 0 {
+		parts = append(parts, fmt.Sprintf("№%d", req.PackSize))
+	}
+
+	return strings.Join(parts, " ")
+}
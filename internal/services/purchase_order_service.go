@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrPurchaseOrderNotFound = errors.New("заказ поставщику не найден")
+
+type PurchaseOrderService interface {
+	CreatePurchaseOrder(req models.PurchaseOrderCreateRequest) (*models.PurchaseOrder, error)
+
+	GetPurchaseOrderByID(id uint) (*models.PurchaseOrder, error)
+
+	ListPurchaseOrders(filter repository.PurchaseOrderFilter) ([]models.PurchaseOrder, error)
+
+	ChangeStatus(id uint, req models.PurchaseOrderStatusRequest) (*models.PurchaseOrder, error)
+
+	ReceiveGoods(id uint, req models.GoodsReceiptRequest) (*models.GoodsReceipt, error)
+
+	ListReceipts(id uint) ([]models.GoodsReceipt, error)
+
+	MarginReport(from, to time.Time) ([]models.MarginReportRow, error)
+}
+
+type purchaseOrderService struct {
+	orders    repository.PurchaseOrderRepository
+	suppliers repository.SupplierRepository
+	medicines repository.MedicineRepository
+	branches  repository.BranchRepository
+}
+
+func NewPurchaseOrderService(
+	orders repository.PurchaseOrderRepository,
+	suppliers repository.SupplierRepository,
+	medicines repository.MedicineRepository,
+	branches repository.BranchRepository,
+) PurchaseOrderService {
+	return &purchaseOrderService{
+		orders:    orders,
+		suppliers: suppliers,
+		medicines: medicines,
+		branches:  branches,
+	}
+}
+
+func (s *purchaseOrderService) CreatePurchaseOrder(req models.PurchaseOrderCreateRequest) (*models.PurchaseOrder, error) {
+	if req.SupplierID == 0 {
+		return nil, errors.New("поле supplier_id должно быть больше 0")
+	}
+
+	if len(req.Lines) == 0 {
+		return nil, errors.New("заказ поставщику должен содержать хотя бы одну строку")
+	}
+
+	if _, err := s.suppliers.GetByID(req.SupplierID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSupplierNotFound
+		}
+		return nil, err
+	}
+
+	if req.BranchID == nil {
+		return nil, ErrBranchRequired
+	}
+
+	if _, err := s.branches.GetByID(*req.BranchID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBranchNotFound
+		}
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		if line.MedicineID == 0 {
+			return nil, errors.New("поле medicine_id должно быть больше 0")
+		}
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if line.PurchasePrice <= 0 {
+			return nil, errors.New("закупочная цена должна быть больше 0")
+		}
+		ids = append(ids, line.MedicineID)
+	}
+
+	medicines, err := s.medicines.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string, len(medicines))
+	for _, medicine := range medicines {
+		names[medicine.ID] = medicine.Name
+	}
+
+	order := &models.PurchaseOrder{
+		SupplierID: req.SupplierID,
+		BranchID:   req.BranchID,
+		Status:     models.PurchaseOrderDraft,
+		Comment:    strings.TrimSpace(req.Comment),
+	}
+
+	for _, line := range req.Lines {
+		name, ok := names[line.MedicineID]
+		if !ok {
+			return nil, ErrMedicineNotFound
+		}
+		order.Lines = append(order.Lines, models.PurchaseOrderLine{
+			MedicineID:    line.MedicineID,
+			MedicineName:  name,
+			Quantity:      line.Quantity,
+			PurchasePrice: line.PurchasePrice,
+		})
+	}
+
+	if err := s.orders.Create(order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *purchaseOrderService) GetPurchaseOrderByID(id uint) (*models.PurchaseOrder, error) {
+	order, err := s.orders.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPurchaseOrderNotFound
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *purchaseOrderService) ListPurchaseOrders(filter repository.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	return s.orders.List(filter)
+}
+
+func (s *purchaseOrderService) ChangeStatus(id uint, req models.PurchaseOrderStatusRequest) (*models.PurchaseOrder, error) {
+	order, err := s.GetPurchaseOrderByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != models.PurchaseOrderDraft || req.Status != models.PurchaseOrderSent {
+		return nil, ErrInvalidStatusChange
+	}
+
+	now := time.Now()
+	order.Status = models.PurchaseOrderSent
+	order.SentAt = &now
+
+	if err := s.orders.UpdateStatus(order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *purchaseOrderService) ReceiveGoods(id uint, req models.GoodsReceiptRequest) (*models.GoodsReceipt, error) {
+	order, err := s.GetPurchaseOrderByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Items) == 0 {
+		return nil, errors.New("приёмка должна содержать хотя бы одну позицию")
+	}
+
+	items := make([]models.GoodsReceiptItemRequest, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.LineID == 0 {
+			return nil, errors.New("поле line_id должно быть больше 0")
+		}
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+
+		item.LotNumber = strings.TrimSpace(item.LotNumber)
+		if (item.LotNumber == "") != (item.ExpiryDate == nil) {
+			return nil, errors.New("поля lot_number и expiry_date указываются вместе")
+		}
+		if item.ExpiryDate != nil && !item.ExpiryDate.After(time.Now()) {
+			return nil, errors.New("нельзя принять партию с истёкшим сроком годности")
+		}
+		if item.ExpiryDate != nil && order.BranchID == nil {
+			return nil, ErrBranchRequired
+		}
+		items = append(items, item)
+	}
+
+	receipt, err := s.orders.Receive(id, items, strings.TrimSpace(req.Actor), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+func (s *purchaseOrderService) ListReceipts(id uint) ([]models.GoodsReceipt, error) {
+	if _, err := s.GetPurchaseOrderByID(id); err != nil {
+		return nil, err
+	}
+
+	return s.orders.ListReceipts(id)
+}
+
+func (s *purchaseOrderService) MarginReport(from, to time.Time) ([]models.MarginReportRow, error) {
+	if !from.Before(to) {
+		return nil, errors.New("дата from должна быть раньше даты to")
+	}
+
+	rows, err := s.orders.MarginReport(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []models.MarginReportRow{}
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultRecommendationLimit = 5
+	MaxRecommendationLimit     = 20
+	MinCoPurchaseOrders        = 2
+)
+
+type RecommendationService interface {
+	RebuildCoPurchases() (int64, error)
+
+	GetRecommendations(medicineID uint, limit int) ([]models.MedicineRecommendation, error)
+}
+
+type recommendationService struct {
+	recommendations repository.RecommendationRepository
+	medicines       repository.MedicineRepository
+	images          MedicineImageService
+}
+
+func NewRecommendationService(
+	recommendations repository.RecommendationRepository,
+	medicines repository.MedicineRepository,
+	images MedicineImageService,
+) RecommendationService {
+	return &recommendationService{
+		recommendations: recommendations,
+		medicines:       medicines,
+		images:          images,
+	}
+}
+
+func (s *recommendationService) RebuildCoPurchases() (int64, error) {
+	return s.recommendations.RebuildCoPurchases(time.Now())
+}
+
+func (s *recommendationService) GetRecommendations(medicineID uint, limit int) ([]models.MedicineRecommendation, error) {
+	if limit <= 0 {
+		limit = DefaultRecommendationLimit
+	}
+	if limit > MaxRecommendationLimit {
+		limit = MaxRecommendationLimit
+	}
+
+	medicine, err := s.medicines.GetByID(medicineID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMedicineNotFound
+		}
+		return nil, err
+	}
+
+	coPurchased, err := s.recommendations.ListCoPurchased(medicineID, MinCoPurchaseOrders, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[uint]models.RecommendationSource, limit)
+	ranked := make([]repository.RecommendationRow, 0, limit)
+	exclude := []uint{medicineID}
+
+	for _, row := range coPurchased {
+		sources[row.MedicineID] = models.RecommendationBoughtTogether
+		ranked = append(ranked, row)
+		exclude = append(exclude, row.MedicineID)
+	}
+
+	if len(ranked) < limit {
+		bestsellers, err := s.recommendations.ListSubcategoryBestsellers(medicine.SubcategoryID, exclude, limit-len(ranked))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range bestsellers {
+			sources[row.MedicineID] = models.RecommendationSubcategoryBestseller
+			ranked = append(ranked, row)
+		}
+	}
+
+	ids := make([]uint, 0, len(ranked))
+	for _, row := range ranked {
+		ids = append(ids, row.MedicineID)
+	}
+
+	medicines, err := s.medicines.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.images.AttachImages(medicines); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Medicine, len(medicines))
+	for _, m := range medicines {
+		byID[m.ID] = m
+	}
+
+	recommendations := make([]models.MedicineRecommendation, 0, len(ranked))
+	for _, row := range ranked {
+		m, ok := byID[row.MedicineID]
+		if !ok {
+			continue
+		}
+		recommendations = append(recommendations, models.MedicineRecommendation{
+			Medicine: m,
+			Source:   sources[row.MedicineID],
+			Score:    row.Score,
+		})
+	}
+
+	return recommendations, nil
+}
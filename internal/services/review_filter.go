@@ -0,0 +1,73 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type ReviewFilter struct {
+	words    []string
+	patterns []*regexp.Regexp
+}
+
+type reviewFilterConfig struct {
+	Words    []string `json:"words"`
+	Patterns []string `json:"patterns"`
+}
+
+func NewReviewFilter(words, patterns []string) (*ReviewFilter, error) {
+	filter := &ReviewFilter{}
+
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			filter.words = append(filter.words, word)
+		}
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное регулярное выражение %q: %w", pattern, err)
+		}
+		filter.patterns = append(filter.patterns, re)
+	}
+
+	return filter, nil
+}
+
+func LoadReviewFilter(path string) (*ReviewFilter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config reviewFilterConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("файл фильтра отзывов %s: %w", path, err)
+	}
+
+	return NewReviewFilter(config.Words, config.Patterns)
+}
+
+func (f *ReviewFilter) Check(text string) []string {
+	lower := strings.ToLower(text)
+
+	var matches []string
+	for _, word := range f.words {
+		if strings.Contains(lower, word) {
+			matches = append(matches, word)
+		}
+	}
+
+	for _, re := range f.patterns {
+		if match := re.FindString(text); match != "" {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
 	"github.com/kuduzow/team-4-pharmacy/internal/repository"
@@ -9,34 +11,71 @@ import (
 )
 
 var ReviewNotFound = errors.New("Not Found")
+var ErrReviewAlreadyExists = repository.ErrReviewAlreadyExists
+var ErrInvalidReviewStatus = errors.New("допустимые статусы отзыва: pending, approved, rejected")
+var ErrRejectionReasonRequired = errors.New("поле reason не должно быть пустым")
+var ErrInvalidReviewSort = errors.New("допустимые сортировки отзывов: newest, rating_desc, rating_asc, helpful")
+var ErrReviewVoteNotFound = errors.New("голос не найден")
+var ErrOwnReviewVote = errors.New("нельзя голосовать за собственный отзыв")
 
 type ModelService interface {
 	CreateReview(req models.ReviewForPost) (*models.Review, error)
 	UpdateReview(id uint, req models.ReviewForUpdate) (*models.Review, error)
 	DeleteReview(id uint) error
-	ListByMedicineID(medicineID uint) ([]models.Review, error)
+	ListByMedicineID(medicineID uint, filter repository.ReviewListFilter) (*models.ReviewListResponse, error)
 	GetAvgRating(medicineID uint) (float64, error)
 	GetByID(id uint) (*models.Review, error)
+	RecomputeRatings() (int64, error)
+	ListForModeration(status string, filter repository.ReviewListFilter) (*models.ReviewListResponse, error)
+	ApproveReview(id uint, req models.ReviewModerationRequest) (*models.Review, error)
+	RejectReview(id uint, req models.ReviewModerationRequest) (*models.Review, error)
+	VoteReview(id uint, req models.ReviewVoteRequest) (*models.Review, error)
+	RemoveVote(id, userID uint) error
 }
 
 type ReviewService struct {
-	repo repository.ReviewRepository
+	repo   repository.ReviewRepository
+	users  repository.UserRepository
+	filter *ReviewFilter
 }
 
-func NewReviewService(repo repository.ReviewRepository) ModelService {
-	return &ReviewService{repo: repo}
+func NewReviewService(repo repository.ReviewRepository, users repository.UserRepository, filter *ReviewFilter) ModelService {
+	return &ReviewService{repo: repo, users: users, filter: filter}
 }
 
 func (s *ReviewService) CreateReview(req models.ReviewForPost) (*models.Review, error) {
+	if _, err := s.users.GetByID(req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByUserAndMedicine(req.UserID, req.MedicineID); err == nil {
+		return nil, ErrReviewAlreadyExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	verified, err := s.repo.HasCompletedPurchase(req.UserID, req.MedicineID)
+	if err != nil {
+		return nil, err
+	}
+
 	review := models.Review{
-		UserID:     req.UserID,
-		MedicineID: req.MedicineID,
-		Rating:     req.Rating,
-		Text:       req.Text,
+		UserID:           req.UserID,
+		MedicineID:       req.MedicineID,
+		Rating:           req.Rating,
+		Text:             req.Text,
+		VerifiedPurchase: verified,
 	}
+	s.submitForModeration(&review)
 
-	err := s.repo.Create(&review)
+	err = s.repo.Create(&review)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMedicineNotFound
+		}
 		return nil, err
 	}
 
@@ -59,8 +98,9 @@ func (s *ReviewService) UpdateReview(id uint, req models.ReviewForUpdate) (*mode
 		review.Rating = *req.Rating
 	}
 
-	if req.Text != nil {
+	if req.Text != nil && *req.Text != review.Text {
 		review.Text = *req.Text
+		s.submitForModeration(review)
 	}
 
 	err = s.repo.Update(review)
@@ -82,33 +122,58 @@ func (s *ReviewService) DeleteReview(id uint) error {
 	return s.repo.Delete(review.ID)
 }
 
-func (s *ReviewService) ListByMedicineID(medicineID uint) ([]models.Review, error) {
-	reviews, err := s.repo.ListByMedicineID(medicineID)
+func (s *ReviewService) ListByMedicineID(medicineID uint, filter repository.ReviewListFilter) (*models.ReviewListResponse, error) {
+	if filter.Sort == "" {
+		filter.Sort = repository.ReviewSortNewest
+	}
+
+	switch filter.Sort {
+	case repository.ReviewSortNewest, repository.ReviewSortRatingDesc, repository.ReviewSortRatingAsc, repository.ReviewSortHelpful:
+	default:
+		return nil, ErrInvalidReviewSort
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultMedicinePageLimit
+	}
+
+	if filter.Limit > MaxMedicinePageLimit {
+		filter.Limit = MaxMedicinePageLimit
+	}
+
+	reviews, total, err := s.repo.ListByMedicineID(medicineID, filter)
 	if err != nil {
 		return nil, err
 	}
-	return reviews, nil
-}
 
-func (s *ReviewService) GetAvgRating(medicineID uint) (float64, error) {
-	reviews, err := s.repo.ListByMedicineID(medicineID)
+	distribution, err := s.repo.RatingDistribution(medicineID)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if len(reviews) == 0 {
-		return 0, nil
+	response := &models.ReviewListResponse{
+		Items:              reviews,
+		Total:              total,
+		RatingDistribution: distribution,
 	}
 
-	accumulator := 0
-	for _, v := range reviews {
-		accumulator += int(v.Rating)
+	if next := filter.Offset + len(reviews); len(reviews) > 0 && int64(next) < total {
+		response.NextPageToken = EncodePageToken(next)
 	}
 
-	avg := float64(accumulator) / float64(len(reviews))
+	return response, nil
+}
 
-	return avg, nil
+func (s *ReviewService) GetAvgRating(medicineID uint) (float64, error) {
+	medicine, err := s.repo.GetRating(medicineID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrMedicineNotFound
+		}
+		return 0, err
+	}
 
+	return medicine.AvgRating, nil
 }
 
 func (s *ReviewService) GetByID(id uint) (*models.Review, error) {
@@ -123,3 +188,137 @@ func (s *ReviewService) GetByID(id uint) (*models.Review, error) {
 
 	return review, nil
 }
+
+func (s *ReviewService) RecomputeRatings() (int64, error) {
+	return s.repo.RecomputeRatings()
+}
+
+func (s *ReviewService) ListForModeration(status string, filter repository.ReviewListFilter) (*models.ReviewListResponse, error) {
+	reviewStatus := models.ReviewPending
+	if status != "" {
+		reviewStatus = models.ReviewStatus(status)
+	}
+
+	switch reviewStatus {
+	case models.ReviewPending, models.ReviewApproved, models.ReviewRejected:
+	default:
+		return nil, ErrInvalidReviewStatus
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultMedicinePageLimit
+	}
+
+	if filter.Limit > MaxMedicinePageLimit {
+		filter.Limit = MaxMedicinePageLimit
+	}
+
+	reviews, total, err := s.repo.ListByStatus(reviewStatus, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ReviewListResponse{
+		Items: reviews,
+		Total: total,
+	}
+
+	if next := filter.Offset + len(reviews); len(reviews) > 0 && int64(next) < total {
+		response.NextPageToken = EncodePageToken(next)
+	}
+
+	return response, nil
+}
+
+func (s *ReviewService) ApproveReview(id uint, req models.ReviewModerationRequest) (*models.Review, error) {
+	return s.moderate(id, models.ReviewApproved, "", req.Actor)
+}
+
+func (s *ReviewService) RejectReview(id uint, req models.ReviewModerationRequest) (*models.Review, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	return s.moderate(id, models.ReviewRejected, reason, req.Actor)
+}
+
+func (s *ReviewService) moderate(id uint, status models.ReviewStatus, reason, actor string) (*models.Review, error) {
+	review, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	review.Status = status
+	review.RejectionReason = reason
+	review.ModeratedBy = strings.TrimSpace(actor)
+	review.ModeratedAt = &now
+
+	if err := s.repo.Update(review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *ReviewService) VoteReview(id uint, req models.ReviewVoteRequest) (*models.Review, error) {
+	review, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.Status != models.ReviewApproved {
+		return nil, ReviewNotFound
+	}
+
+	if review.UserID == req.UserID {
+		return nil, ErrOwnReviewVote
+	}
+
+	if _, err := s.users.GetByID(req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	vote := &models.ReviewVote{
+		ReviewID: id,
+		UserID:   req.UserID,
+		Helpful:  *req.Helpful,
+	}
+
+	if err := s.repo.UpsertVote(vote); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+func (s *ReviewService) RemoveVote(id, userID uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteVote(id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrReviewVoteNotFound
+	}
+
+	return nil
+}
+
+func (s *ReviewService) submitForModeration(review *models.Review) {
+	review.Status = models.ReviewPending
+	review.RejectionReason = ""
+	review.ModeratedBy = ""
+	review.ModeratedAt = nil
+
+	matches := s.filter.Check(review.Text)
+	review.Flagged = len(matches) > 0
+	review.FlagReason = strings.Join(matches, ", ")
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/notifications"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrSubscriptionNotFound = errors.New("подписка не найдена")
+var ErrInvalidSubscriptionType = errors.New("допустимые типы подписки: back_in_stock, price_drop")
+var ErrAlreadyInStock = errors.New("лекарство уже в наличии")
+var ErrInvalidTargetPrice = errors.New("целевая цена должна быть больше 0 и ниже текущей цены")
+
+const subscriptionClaimTimeout = 10 * time.Minute
+
+type SubscriptionService interface {
+	Subscribe(medicineID uint, req models.SubscriptionCreateRequest) (*models.MedicineSubscription, error)
+
+	ListUserSubscriptions(userID uint) ([]models.MedicineSubscription, error)
+
+	Unsubscribe(userID, id uint) error
+
+	NotifyDue(ctx context.Context) (int, error)
+}
+
+type subscriptionService struct {
+	subscriptions repository.SubscriptionRepository
+	medicines     repository.MedicineRepository
+	users         repository.UserRepository
+	notifier      notifications.Notifier
+	logger        *slog.Logger
+}
+
+func NewSubscriptionService(
+	subscriptions repository.SubscriptionRepository,
+	medicines repository.MedicineRepository,
+	users repository.UserRepository,
+	notifier notifications.Notifier,
+) SubscriptionService {
+	return &subscriptionService{
+		subscriptions: subscriptions,
+		medicines:     medicines,
+		users:         users,
+		notifier:      notifier,
+		logger:        slog.Default(),
+	}
+}
+
+func (s *subscriptionService) Subscribe(medicineID uint, req models.SubscriptionCreateRequest) (*models.MedicineSubscription, error) {
+	if req.UserID == 0 {
+		return nil, errors.New("поле user_id должно быть больше 0")
+	}
+
+	if _, err := s.users.GetByID(req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	medicine, err := s.medicines.GetByID(medicineID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMedicineNotFound
+		}
+		return nil, err
+	}
+
+	subscription := &models.MedicineSubscription{
+		UserID:     req.UserID,
+		MedicineID: medicineID,
+		Type:       req.Type,
+	}
+
+	switch req.Type {
+	case models.SubscriptionBackInStock:
+		if medicine.InStock && medicine.StockQuantity > 0 {
+			return nil, ErrAlreadyInStock
+		}
+	case models.SubscriptionPriceDrop:
+		if req.TargetPrice == nil || *req.TargetPrice <= 0 || *req.TargetPrice >= medicine.Price {
+			return nil, ErrInvalidTargetPrice
+		}
+		subscription.TargetPrice = req.TargetPrice
+	default:
+		return nil, ErrInvalidSubscriptionType
+	}
+
+	if err := s.subscriptions.Upsert(subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *subscriptionService) ListUserSubscriptions(userID uint) ([]models.MedicineSubscription, error) {
+	if _, err := s.users.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return s.subscriptions.ListByUserID(userID)
+}
+
+func (s *subscriptionService) Unsubscribe(userID, id uint) error {
+	subscription, err := s.subscriptions.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubscriptionNotFound
+		}
+		return err
+	}
+
+	if subscription.UserID != userID {
+		return ErrSubscriptionNotFound
+	}
+
+	return s.subscriptions.Delete(id)
+}
+
+func (s *subscriptionService) NotifyDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := s.subscriptions.ClaimDue(now, now.Add(-subscriptionClaimTimeout))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, subscription := range due {
+		if err := s.notifier.Notify(ctx, subscriptionNotification(subscription)); err != nil {
+			s.logger.Error("subscription_service.NotifyDue: failed to send notification",
+				slog.Uint64("subscription_id", uint64(subscription.ID)),
+				slog.String("error", err.Error()),
+			)
+			if err := s.subscriptions.ReleaseClaim(subscription.ID); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		if err := s.subscriptions.Delete(subscription.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func subscriptionNotification(subscription models.DueSubscription) notifications.Notification {
+	notification := notifications.Notification{
+		Type:      string(subscription.Type),
+		Recipient: subscription.Email,
+		Data: map[string]any{
+			"subscription_id": subscription.ID,
+			"user_id":         subscription.UserID,
+			"medicine_id":     subscription.MedicineID,
+			"price":           subscription.Price,
+			"stock_quantity":  subscription.StockQuantity,
+		},
+	}
+
+	switch subscription.Type {
+	case models.SubscriptionPriceDrop:
+		notification.Subject = "Снижение цены: " + subscription.MedicineName
+		notification.Message = fmt.Sprintf("Цена снизилась до %.2f (ваша целевая цена %.2f)", subscription.Price, *subscription.TargetPrice)
+		notification.Data["target_price"] = *subscription.TargetPrice
+	default:
+		notification.Subject = "Снова в наличии: " + subscription.MedicineName
+		notification.Message = fmt.Sprintf("В наличии %d шт.", subscription.StockQuantity)
+	}
+
+	return notification
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrSupplierNotFound = errors.New("поставщик не найден")
+var ErrSupplierInUse = errors.New("у поставщика есть заказы, удаление невозможно")
+
+type SupplierService interface {
+	CreateSupplier(req models.SupplierCreateRequest) (*models.Supplier, error)
+
+	GetSupplierByID(id uint) (*models.Supplier, error)
+
+	GetAllSuppliers() ([]models.Supplier, error)
+
+	UpdateSupplier(id uint, req models.SupplierUpdateRequest) (*models.Supplier, error)
+
+	DeleteSupplier(id uint) error
+}
+
+type supplierService struct {
+	suppliers repository.SupplierRepository
+}
+
+func NewSupplierService(suppliers repository.SupplierRepository) SupplierService {
+	return &supplierService{suppliers: suppliers}
+}
+
+func (s *supplierService) CreateSupplier(req models.SupplierCreateRequest) (*models.Supplier, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("поле name не должно быть пустым")
+	}
+
+	supplier := &models.Supplier{
+		Name:    name,
+		INN:     strings.TrimSpace(req.INN),
+		Phone:   strings.TrimSpace(req.Phone),
+		Email:   strings.TrimSpace(req.Email),
+		Address: strings.TrimSpace(req.Address),
+	}
+
+	if err := s.suppliers.Create(supplier); err != nil {
+		return nil, err
+	}
+
+	return supplier, nil
+}
+
+func (s *supplierService) GetSupplierByID(id uint) (*models.Supplier, error) {
+	supplier, err := s.suppliers.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSupplierNotFound
+		}
+		return nil, err
+	}
+
+	return supplier, nil
+}
+
+func (s *supplierService) GetAllSuppliers() ([]models.Supplier, error) {
+	return s.suppliers.GetAll()
+}
+
+func (s *supplierService) UpdateSupplier(id uint, req models.SupplierUpdateRequest) (*models.Supplier, error) {
+	supplier, err := s.GetSupplierByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("поле name не должно быть пустым")
+		}
+		supplier.Name = name
+	}
+
+	if req.INN != nil {
+		supplier.INN = strings.TrimSpace(*req.INN)
+	}
+
+	if req.Phone != nil {
+		supplier.Phone = strings.TrimSpace(*req.Phone)
+	}
+
+	if req.Email != nil {
+		supplier.Email = strings.TrimSpace(*req.Email)
+	}
+
+	if req.Address != nil {
+		supplier.Address = strings.TrimSpace(*req.Address)
+	}
+
+	if err := s.suppliers.Update(supplier); err != nil {
+		return nil, err
+	}
+
+	return supplier, nil
+}
+
+func (s *supplierService) DeleteSupplier(id uint) error {
+	if _, err := s.GetSupplierByID(id); err != nil {
+		return err
+	}
+
+	count, err := s.suppliers.CountPurchaseOrders(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSupplierInUse
+	}
+
+	return s.suppliers.Delete(id)
+}
@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type ActiveIngredientHandler struct {
+	service services.ActiveIngredientService
+}
+
+func NewActiveIngredientHandler(service services.ActiveIngredientService) *ActiveIngredientHandler {
+	return &ActiveIngredientHandler{service: service}
+}
+
+func (h *ActiveIngredientHandler) RegisterRoutes(r *gin.Engine) {
+	ingredients := r.Group("/ingredients")
+	{
+		ingredients.POST("", h.Create)
+		ingredients.GET("", h.GetAll)
+	}
+
+	medicines := r.Group("/medicines/:id")
+	{
+		medicines.GET("/ingredients", h.GetMedicineIngredients)
+		medicines.PUT("/ingredients", h.SetMedicineIngredients)
+		medicines.GET("/analogs", h.GetAnalogs)
+	}
+}
+
+func (h *ActiveIngredientHandler) Create(c *gin.Context) {
+	var req models.ActiveIngredientCreateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	ingredient, err := h.service.CreateIngredient(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ingredient)
+}
+
+func (h *ActiveIngredientHandler) GetAll(c *gin.Context) {
+	ingredients, err := h.service.GetAllIngredients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ingredients)
+}
+
+func (h *ActiveIngredientHandler) GetMedicineIngredients(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	ingredients, err := h.service.GetMedicineIngredients(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ingredients)
+}
+
+func (h *ActiveIngredientHandler) SetMedicineIngredients(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req []models.MedicineIngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	ingredients, err := h.service.SetMedicineIngredients(uint(id), req)
+	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) || errors.Is(err, services.ErrActiveIngredientNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ingredients)
+}
+
+func (h *ActiveIngredientHandler) GetAnalogs(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	analogs, err := h.service.GetAnalogs(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, analogs)
+}
@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type BatchHandler struct {
+	service services.BatchService
+}
+
+func NewBatchHandler(service services.BatchService) *BatchHandler {
+	return &BatchHandler{service: service}
+}
+
+func (h *BatchHandler) RegisterRoutes(r *gin.Engine) {
+	medicines := r.Group("/medicines/:id")
+	{
+		medicines.POST("/batches", h.Create)
+		medicines.GET("/batches", h.List)
+	}
+}
+
+func (h *BatchHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.BatchCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	batch, err := h.service.CreateBatch(uint(id), req)
+	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) || errors.Is(err, services.ErrBranchNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, batch)
+}
+
+func (h *BatchHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	batches, err := h.service.ListBatches(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
@@ -0,0 +1,147 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type BranchHandler struct {
+	service services.BranchService
+}
+
+func NewBranchHandler(service services.BranchService) *BranchHandler {
+	return &BranchHandler{service: service}
+}
+
+func (h *BranchHandler) RegisterRoutes(r *gin.Engine) {
+	branches := r.Group("/branches")
+	{
+		branches.POST("", h.Create)
+		branches.GET("", h.GetAll)
+		branches.GET("/:id", h.Get)
+		branches.PATCH("/:id", h.Update)
+		branches.DELETE("/:id", h.Delete)
+	}
+
+	r.GET("/medicines/:id/availability", h.Availability)
+}
+
+func (h *BranchHandler) Create(c *gin.Context) {
+	var req models.BranchCreateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	branch, err := h.service.CreateBranch(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, branch)
+}
+
+func (h *BranchHandler) GetAll(c *gin.Context) {
+	branches, err := h.service.GetAllBranches()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, branches)
+}
+
+func (h *BranchHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	branch, err := h.service.GetBranchByID(uint(id))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, branch)
+}
+
+func (h *BranchHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.BranchUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	branch, err := h.service.UpdateBranch(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, branch)
+}
+
+func (h *BranchHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	if err := h.service.DeleteBranch(uint(id)); err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *BranchHandler) Availability(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	lat, lng, err := parseCoordinates(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	availability, err := h.service.GetAvailability(uint(id), lat, lng)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+func (h *BranchHandler) writeServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBranchNotFound), errors.Is(err, services.ErrMedicineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrBranchInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCoordinates), errors.Is(err, services.ErrEmptyName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
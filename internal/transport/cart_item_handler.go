@@ -46,7 +46,8 @@ func (h *CartItemHandler) AddItem(c *gin.Context) {
 
 	updated, err := h.service.AddItem(uint(id), req)
 	if err != nil {
-		if errors.Is(err, services.ErrInvalidQuantity) || errors.Is(err, services.ErrOutOfStock) || errors.Is(err, services.ErrMedicineMissing) {
+		if errors.Is(err, services.ErrInvalidQuantity) || errors.Is(err, services.ErrOutOfStock) || errors.Is(err, services.ErrMedicineMissing) ||
+			errors.Is(err, services.ErrInvalidBarcode) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
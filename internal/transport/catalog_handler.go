@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+var catalogContentTypes = map[string]string{
+	services.CatalogFormatCSV:  "text/csv; charset=utf-8",
+	services.CatalogFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type CatalogHandler struct {
+	service services.CatalogService
+}
+
+func NewCatalogHandler(service services.CatalogService) *CatalogHandler {
+	return &CatalogHandler{service: service}
+}
+
+func (h *CatalogHandler) RegisterRoutes(r *gin.Engine) {
+	medicines := r.Group("/medicines")
+	{
+		medicines.POST("/import", h.Import)
+		medicines.GET("/import/:id", h.GetImportJob)
+		medicines.GET("/export", h.Export)
+	}
+}
+
+func (h *CatalogHandler) Import(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "загрузите файл каталога в поле file"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	job, err := h.service.StartImport(header.Filename, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCatalogFormat),
+			errors.Is(err, services.ErrEmptyCatalogFile),
+			errors.Is(err, services.ErrInvalidCatalogFile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (h *CatalogHandler) GetImportJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	job, err := h.service.GetImportJob(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *CatalogHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", services.CatalogFormatCSV)
+	contentType, ok := catalogContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrUnsupportedCatalogFormat.Error()})
+		return
+	}
+
+	filter, err := parseMedicineFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.service.Export(&buf, format, filter); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=catalog."+format)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
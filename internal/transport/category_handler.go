@@ -32,7 +32,11 @@ func (h *CategoryHandler) RegisterRoutes(r *gin.Engine) {
 		categories.POST("", h.Create)
 		categories.POST("/subcategory", h.CreateSubcategory)
 		categories.GET("/:id/subcategory", h.GetSubcategoriesByCategoryID)
-
+		categories.GET("/tree", h.GetTree)
+		categories.PATCH("/:id", h.Update)
+		categories.DELETE("/:id", h.Delete)
+		categories.PATCH("/subcategory/:id", h.UpdateSubcategory)
+		categories.DELETE("/subcategory/:id", h.DeleteSubcategory)
 	}
 }
 
@@ -123,3 +127,143 @@ func (h *CategoryHandler) GetSubcategoriesByCategoryID(c *gin.Context) {
 	h.logger.Info("category_handler.GetSubcategoriesByCategoryID: subcategories retrieved successfully", slog.Uint64("category_id", id), slog.Int("count", len(subcategory)))
 	c.JSON(http.StatusOK, subcategory)
 }
+
+func (h *CategoryHandler) GetTree(c *gin.Context) {
+	h.logger.Info("category_handler.GetTree: processing GET /categories/tree request")
+
+	tree, err := h.service.GetTree()
+	if err != nil {
+		h.logger.Error("category_handler.GetTree: service error", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("category_handler.GetTree: category tree retrieved successfully", slog.Int("count", len(tree)))
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *CategoryHandler) Update(c *gin.Context) {
+	h.logger.Info("category_handler.Update: processing PATCH /categories/:id request")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("category_handler.Update: invalid id parameter", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.UpdateCategory
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("category_handler.Update: invalid request body", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.service.UpdateCategory(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, "category_handler.Update", err)
+		return
+	}
+
+	h.logger.Info("category_handler.Update: category updated successfully", slog.Uint64("id", id))
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	h.logger.Info("category_handler.Delete: processing DELETE /categories/:id request")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("category_handler.Delete: invalid id parameter", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reassignTo, err := parseUintQuery(c, "reassign_to")
+	if err != nil {
+		h.logger.Error("category_handler.Delete: invalid reassign_to parameter", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DeleteCategory(uint(id), reassignTo); err != nil {
+		h.writeServiceError(c, "category_handler.Delete", err)
+		return
+	}
+
+	h.logger.Info("category_handler.Delete: category deleted successfully", slog.Uint64("id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+func (h *CategoryHandler) UpdateSubcategory(c *gin.Context) {
+	h.logger.Info("category_handler.UpdateSubcategory: processing PATCH /categories/subcategory/:id request")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("category_handler.UpdateSubcategory: invalid id parameter", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.UpdateSubcategory
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("category_handler.UpdateSubcategory: invalid request body", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subcategory, err := h.service.UpdateSubcategory(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, "category_handler.UpdateSubcategory", err)
+		return
+	}
+
+	h.logger.Info("category_handler.UpdateSubcategory: subcategory updated successfully", slog.Uint64("id", id))
+	c.JSON(http.StatusOK, subcategory)
+}
+
+func (h *CategoryHandler) DeleteSubcategory(c *gin.Context) {
+	h.logger.Info("category_handler.DeleteSubcategory: processing DELETE /categories/subcategory/:id request")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Error("category_handler.DeleteSubcategory: invalid id parameter", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reassignTo, err := parseUintQuery(c, "reassign_to")
+	if err != nil {
+		h.logger.Error("category_handler.DeleteSubcategory: invalid reassign_to parameter", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DeleteSubcategory(uint(id), reassignTo); err != nil {
+		h.writeServiceError(c, "category_handler.DeleteSubcategory", err)
+		return
+	}
+
+	h.logger.Info("category_handler.DeleteSubcategory: subcategory deleted successfully", slog.Uint64("id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+func (h *CategoryHandler) writeServiceError(c *gin.Context, op string, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound), errors.Is(err, services.ErrSubcategoryNotFound):
+		h.logger.Warn(op+": not found", slog.String("error", err.Error()))
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCategoryInUse), errors.Is(err, services.ErrSubcategoryInUse):
+		h.logger.Warn(op+": conflict", slog.String("error", err.Error()))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidReassignTarget):
+		h.logger.Warn(op+": invalid reassign target", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmptyName), errors.Is(err, services.ErrEmptyCategoryID):
+		h.logger.Warn(op+": validation error", slog.String("error", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(op+": service error", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,254 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type InventoryHandler struct {
+	service services.InventoryService
+}
+
+func NewInventoryHandler(service services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{service: service}
+}
+
+func (h *InventoryHandler) RegisterRoutes(r *gin.Engine) {
+	inventory := r.Group("/inventory")
+	{
+		inventory.GET("/expiring", h.ListExpiring)
+		inventory.POST("/write-offs", h.WriteOff)
+		inventory.GET("/write-offs/:id", h.GetWriteOff)
+		inventory.GET("/write-offs/:id/report", h.WriteOffReport)
+		inventory.GET("/reconciliation", h.Reconcile)
+		inventory.GET("/low-stock", h.ListLowStock)
+		inventory.GET("/reorder-suggestions", h.ReorderSuggestions)
+	}
+
+	medicines := r.Group("/medicines/:id")
+	{
+		medicines.GET("/stock-history", h.StockHistory)
+		medicines.POST("/stock-adjustments", h.AdjustStock)
+	}
+}
+
+func (h *InventoryHandler) ListExpiring(c *gin.Context) {
+	days := services.DefaultExpiringDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный параметр days"})
+			return
+		}
+		days = parsed
+	}
+
+	batches, err := h.service.ListExpiring(days)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidExpiringDays) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+func (h *InventoryHandler) WriteOff(c *gin.Context) {
+	var req models.WriteOffCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный JSON"})
+		return
+	}
+
+	writeOff, err := h.service.WriteOffExpired(req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNothingToWriteOff) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrWriteOffReasonRequired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, writeOff)
+}
+
+func (h *InventoryHandler) GetWriteOff(c *gin.Context) {
+	writeOff, ok := h.loadWriteOff(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, writeOff)
+}
+
+func (h *InventoryHandler) WriteOffReport(c *gin.Context) {
+	writeOff, ok := h.loadWriteOff(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.service.WriteOffReportCSV(&buf, writeOff); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=write-off-%d.csv", writeOff.ID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный id"})
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный JSON"})
+		return
+	}
+
+	movement, err := h.service.AdjustStock(uint(id), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMedicineNotFound), errors.Is(err, services.ErrBatchNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOutOfStock):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, movement)
+}
+
+func (h *InventoryHandler) StockHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный id"})
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.service.GetStockHistory(uint(id), limit, offset)
+	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *InventoryHandler) Reconcile(c *gin.Context) {
+	onlyMismatched, err := parseBoolQuery(c, "only_mismatched")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.service.Reconcile(onlyMismatched != nil && *onlyMismatched)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
+
+func (h *InventoryHandler) ListLowStock(c *gin.Context) {
+	alerts, err := h.service.ListLowStock()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (h *InventoryHandler) ReorderSuggestions(c *gin.Context) {
+	days, err := parseIntQuery(c, "days", services.DefaultSalesPeriodDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	coverDays, err := parseIntQuery(c, "cover_days", services.DefaultCoverDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	suggestions, err := h.service.ReorderSuggestions(days, coverDays)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSalesPeriod) || errors.Is(err, services.ErrInvalidCoverDays) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (h *InventoryHandler) loadWriteOff(c *gin.Context) (*models.WriteOff, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный id"})
+		return nil, false
+	}
+
+	writeOff, err := h.service.GetWriteOff(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrWriteOffNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return writeOff, true
+}
+
+func parseIntQuery(c *gin.Context, key string, fallback int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("некорректный параметр %s", key)
+	}
+	return value, nil
+}
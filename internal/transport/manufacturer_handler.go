@@ -0,0 +1,144 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type ManufacturerHandler struct {
+	service services.ManufacturerService
+}
+
+func NewManufacturerHandler(service services.ManufacturerService) *ManufacturerHandler {
+	return &ManufacturerHandler{service: service}
+}
+
+func (h *ManufacturerHandler) RegisterRoutes(r *gin.Engine) {
+	manufacturers := r.Group("/manufacturers")
+	{
+		manufacturers.POST("", h.Create)
+		manufacturers.GET("", h.GetAll)
+		manufacturers.GET("/:id", h.Get)
+		manufacturers.PATCH("/:id", h.Update)
+		manufacturers.DELETE("/:id", h.Delete)
+		manufacturers.POST("/:id/merge", h.Merge)
+	}
+}
+
+func (h *ManufacturerHandler) Create(c *gin.Context) {
+	var req models.ManufacturerCreateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	manufacturer, err := h.service.CreateManufacturer(req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, manufacturer)
+}
+
+func (h *ManufacturerHandler) GetAll(c *gin.Context) {
+	manufacturers, err := h.service.GetAllManufacturers(c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, manufacturers)
+}
+
+func (h *ManufacturerHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	manufacturer, err := h.service.GetManufacturerByID(uint(id))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, manufacturer)
+}
+
+func (h *ManufacturerHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.ManufacturerUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	manufacturer, err := h.service.UpdateManufacturer(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, manufacturer)
+}
+
+func (h *ManufacturerHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	if err := h.service.DeleteManufacturer(uint(id)); err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *ManufacturerHandler) Merge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.ManufacturerMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	manufacturer, err := h.service.MergeManufacturers(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, manufacturer)
+}
+
+func (h *ManufacturerHandler) writeServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrManufacturerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrManufacturerInUse), errors.Is(err, services.ErrManufacturerNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
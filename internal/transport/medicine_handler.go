@@ -2,8 +2,11 @@ package transport
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
@@ -24,6 +27,9 @@ func (h *MedicineHandler) RegisterRoutes(r *gin.Engine) {
 	medicines := r.Group("/medicines")
 	{
 		medicines.POST("", h.Create)
+		medicines.GET("/suggest", h.Suggest)
+		medicines.GET("/facets", h.Facets)
+		medicines.GET("/by-barcode/:code", h.GetByBarcode)
 		medicines.GET("/:id", h.Get)
 		medicines.DELETE("/:id", h.Delete)
 		medicines.PATCH("/:id", h.Update)
@@ -44,7 +50,14 @@ func (h *MedicineHandler) Create(c *gin.Context) {
 	medicine, err := h.service.CreateMedicine(req)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, services.ErrBarcodeTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidPackSize), errors.Is(err, services.ErrInvalidReorderLevels):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -61,7 +74,13 @@ func (h *MedicineHandler) Get(c *gin.Context) {
 		return
 	}
 
-	medicine, err := h.service.GetMedicineByID(uint(id))
+	location, err := parseStockLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	medicine, err := h.service.GetMedicineByID(uint(id), location)
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -75,6 +94,29 @@ func (h *MedicineHandler) Get(c *gin.Context) {
 	c.JSON(http.StatusOK, medicine)
 }
 
+func (h *MedicineHandler) GetByBarcode(c *gin.Context) {
+	location, err := parseStockLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	medicine, err := h.service.GetMedicineByBarcode(c.Param("code"), location)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidBarcode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrMedicineNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, medicine)
+}
+
 func (h *MedicineHandler) Update(c *gin.Context) {
 
 	idstr := c.Param("id")
@@ -100,6 +142,16 @@ func (h *MedicineHandler) Update(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, services.ErrStockManagedByBatches) || errors.Is(err, services.ErrBarcodeTaken) ||
+			errors.Is(err, services.ErrSharedProductField) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidBarcode) || errors.Is(err, services.ErrManufacturerNotFound) ||
+			errors.Is(err, services.ErrInvalidPackSize) || errors.Is(err, services.ErrInvalidReorderLevels) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -130,36 +182,269 @@ func (h *MedicineHandler) Delete(c *gin.Context) {
 }
 
 func (h *MedicineHandler) List(c *gin.Context) {
-	var filter repository.MedicineFilter
+	filter, err := parseMedicineFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
-		categoryID, err := strconv.ParseUint(categoryIDStr, 10, 64)
-		if err == nil {
-			categoryIDUint := uint(categoryID)
-			filter.CategoryID = &categoryIDUint
-		}
+	medicines, err := h.service.ListMedicines(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	if subcategoryIDStr := c.Query("subcategory_id"); subcategoryIDStr != "" {
-		subcategoryID, err := strconv.ParseUint(subcategoryIDStr, 10, 64)
-		if err == nil {
-			subcategoryIDUint := uint(subcategoryID)
-			filter.SubcategoryID = &subcategoryIDUint
+	c.JSON(http.StatusOK, medicines)
+}
+
+func (h *MedicineHandler) Suggest(c *gin.Context) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > services.MaxSuggestLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("параметр limit должен быть от 1 до %d", services.MaxSuggestLimit)})
+			return
 		}
+		limit = parsed
 	}
 
-	if inStockStr := c.Query("in_stock"); inStockStr != "" {
-		inStock, err := strconv.ParseBool(inStockStr)
-		if err == nil {
-			filter.InStock = &inStock
+	suggestions, err := h.service.SuggestMedicines(c.Query("q"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrSuggestQueryTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	medicines, err := h.service.ListMedicines(filter)
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (h *MedicineHandler) Facets(c *gin.Context) {
+	filter, err := parseMedicineFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	facets, err := h.service.GetMedicineFacets(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, medicines)
+	c.JSON(http.StatusOK, facets)
+}
+
+func parseMedicineFilter(c *gin.Context) (repository.MedicineFilter, error) {
+	var filter repository.MedicineFilter
+	var err error
+
+	filter.Query = strings.TrimSpace(c.Query("q"))
+
+	if filter.CategoryID, err = parseUintQuery(c, "category_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.SubcategoryID, err = parseUintQuery(c, "subcategory_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.InStock, err = parseBoolQuery(c, "in_stock"); err != nil {
+		return filter, err
+	}
+
+	if filter.Location, err = parseStockLocation(c); err != nil {
+		return filter, err
+	}
+
+	if filter.PrescriptionRequired, err = parseBoolQuery(c, "prescription_required"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinPrice, err = parseFloatQuery(c, "min_price"); err != nil {
+		return filter, err
+	}
+
+	if filter.MaxPrice, err = parseFloatQuery(c, "max_price"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return filter, errors.New("min_price не может быть больше max_price")
+	}
+
+	if filter.MinRating, err = parseFloatQuery(c, "min_rating"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinRating != nil && (*filter.MinRating < 0 || *filter.MinRating > 5) {
+		return filter, errors.New("min_rating должен быть от 0 до 5")
+	}
+
+	if manufacturer := strings.TrimSpace(c.Query("manufacturer")); manufacturer != "" {
+		filter.Manufacturer = &manufacturer
+	}
+
+	if filter.ManufacturerID, err = parseUintQuery(c, "manufacturer_id"); err != nil {
+		return filter, err
+	}
+
+	if sortBy := c.Query("sort"); sortBy != "" {
+		filter.SortBy = repository.MedicineSort(sortBy)
+		if !filter.SortBy.Valid() {
+			return filter, errors.New("некорректный параметр sort: допустимо price, rating, name, newest")
+		}
+		filter.SortDesc = filter.SortBy == repository.MedicineSortNewest
+	}
+
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		filter.SortDesc = false
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, errors.New("некорректный параметр order: допустимо asc, desc")
+	}
+
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		return filter, err
+	}
+
+	if token := c.Query("page_token"); token != "" {
+		offset, err := services.DecodePageToken(token)
+		if err != nil {
+			return filter, err
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
+func parseStockLocation(c *gin.Context) (*repository.StockLocation, error) {
+	var location repository.StockLocation
+	var err error
+
+	if location.BranchID, err = parseUintQuery(c, "branch_id"); err != nil {
+		return nil, err
+	}
+
+	if location.Latitude, location.Longitude, err = parseCoordinates(c); err != nil {
+		return nil, err
+	}
+
+	radius, err := parseFloatQuery(c, "radius_km")
+	if err != nil {
+		return nil, err
+	}
+	if radius != nil {
+		if *radius == 0 {
+			return nil, errors.New("параметр radius_km должен быть больше 0")
+		}
+		location.RadiusKm = *radius
+	}
+
+	if location.BranchID == nil && !location.HasCoordinates() {
+		if radius != nil {
+			return nil, errors.New("параметр radius_km используется только вместе с lat и lng")
+		}
+		return nil, nil
+	}
+
+	if location.BranchID != nil && location.HasCoordinates() {
+		return nil, errors.New("укажите либо branch_id, либо координаты lat и lng")
+	}
+
+	return &location, nil
+}
+
+func parseCoordinates(c *gin.Context) (*float64, *float64, error) {
+	latStr, lngStr := c.Query("lat"), c.Query("lng")
+	if latStr == "" && lngStr == "" {
+		return nil, nil, nil
+	}
+
+	if latStr == "" || lngStr == "" {
+		return nil, nil, errors.New("параметры lat и lng передаются вместе")
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, nil, services.ErrInvalidCoordinates
+	}
+
+	lng, err := strconv.ParseFloat(lngStr, 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, nil, services.ErrInvalidCoordinates
+	}
+
+	return &lat, &lng, nil
+}
+
+func parsePagination(c *gin.Context) (int, int, error) {
+	limit, offset := 0, 0
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > services.MaxMedicinePageLimit {
+			return 0, 0, fmt.Errorf("параметр limit должен быть от 1 до %d", services.MaxMedicinePageLimit)
+		}
+		limit = parsed
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			return 0, 0, errors.New("параметр offset должен быть неотрицательным числом")
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
+
+func parseUintQuery(c *gin.Context, key string) (*uint, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный параметр %s", key)
+	}
+
+	result := uint(value)
+	return &result, nil
+}
+
+func parseBoolQuery(c *gin.Context, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный параметр %s", key)
+	}
+
+	return &value, nil
+}
+
+func parseFloatQuery(c *gin.Context, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("некорректный параметр %s", key)
+	}
+
+	return &value, nil
 }
@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type MedicineImageHandler struct {
+	service services.MedicineImageService
+}
+
+func NewMedicineImageHandler(service services.MedicineImageService) *MedicineImageHandler {
+	return &MedicineImageHandler{service: service}
+}
+
+func (h *MedicineImageHandler) RegisterRoutes(r *gin.Engine) {
+	images := r.Group("/medicines/:id/images")
+	{
+		images.POST("", h.Upload)
+		images.GET("", h.List)
+		images.PUT("/order", h.Reorder)
+		images.PATCH("/:image_id/primary", h.SetPrimary)
+		images.DELETE("/:image_id", h.Delete)
+	}
+
+	r.GET("/media/*key", h.Serve)
+}
+
+func (h *MedicineImageHandler) Upload(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "загрузите изображение в поле file"})
+		return
+	}
+
+	if header.Size > services.MaxImageSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrImageTooLarge.Error()})
+		return
+	}
+
+	primary := false
+	if raw := c.PostForm("primary"); raw != "" {
+		if primary, err = strconv.ParseBool(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный параметр primary"})
+			return
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	image, err := h.service.UploadImage(uint(id), file, primary)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, image)
+}
+
+func (h *MedicineImageHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	images, err := h.service.ListImages(uint(id))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+func (h *MedicineImageHandler) Reorder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.MedicineImageOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	images, err := h.service.ReorderImages(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+func (h *MedicineImageHandler) SetPrimary(c *gin.Context) {
+	id, imageID, ok := parseImageParams(c)
+	if !ok {
+		return
+	}
+
+	images, err := h.service.SetPrimaryImage(id, imageID)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+func (h *MedicineImageHandler) Delete(c *gin.Context) {
+	id, imageID, ok := parseImageParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteImage(id, imageID); err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *MedicineImageHandler) Serve(c *gin.Context) {
+	file, contentType, err := h.service.OpenMedia(c.Param("key"))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+}
+
+func (h *MedicineImageHandler) writeServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMedicineNotFound), errors.Is(err, services.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnsupportedImageType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func parseImageParams(c *gin.Context) (uint, uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return 0, 0, false
+	}
+
+	imageID, err := strconv.ParseUint(c.Param("image_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный image_id"})
+		return 0, 0, false
+	}
+
+	return uint(id), uint(imageID), true
+}
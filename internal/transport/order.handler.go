@@ -101,6 +101,19 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	}
 	order, err := h.service.CreateOrder(req)
 	if err != nil {
+		var interactionsErr *services.InteractionsNotAcknowledgedError
+		if errors.As(err, &interactionsErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "interactions": interactionsErr.Warnings})
+			return
+		}
+		if errors.Is(err, services.ErrOutOfStock) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrBranchNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
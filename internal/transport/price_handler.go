@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type PriceHandler struct {
+	service services.PriceService
+}
+
+func NewPriceHandler(service services.PriceService) *PriceHandler {
+	return &PriceHandler{service: service}
+}
+
+func (h *PriceHandler) RegisterRoutes(r *gin.Engine) {
+	medicines := r.Group("/medicines/:id")
+	{
+		medicines.GET("/price-history", h.History)
+		medicines.POST("/scheduled-prices", h.Schedule)
+		medicines.DELETE("/scheduled-prices/:price_id", h.CancelScheduled)
+	}
+}
+
+func (h *PriceHandler) History(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.service.GetPriceHistory(uint(id), limit, offset)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *PriceHandler) Schedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.ScheduledPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	price, err := h.service.SchedulePrice(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, price)
+}
+
+func (h *PriceHandler) CancelScheduled(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	priceID, err := strconv.ParseUint(c.Param("price_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный price_id"})
+		return
+	}
+
+	if err := h.service.CancelScheduledPrice(uint(id), uint(priceID)); err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *PriceHandler) writeServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMedicineNotFound), errors.Is(err, services.ErrScheduledPriceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrScheduledPriceApplied):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
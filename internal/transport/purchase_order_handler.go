@@ -0,0 +1,201 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+const reportDateLayout = "2006-01-02"
+
+type PurchaseOrderHandler struct {
+	service services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+func (h *PurchaseOrderHandler) RegisterRoutes(r *gin.Engine) {
+	orders := r.Group("/purchase-orders")
+	{
+		orders.POST("", h.Create)
+		orders.GET("", h.List)
+		orders.GET("/:id", h.Get)
+		orders.PATCH("/:id/status", h.ChangeStatus)
+		orders.POST("/:id/receipts", h.Receive)
+		orders.GET("/:id/receipts", h.ListReceipts)
+	}
+
+	r.GET("/reports/margin", h.MarginReport)
+}
+
+func (h *PurchaseOrderHandler) Create(c *gin.Context) {
+	var req models.PurchaseOrderCreateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	order, err := h.service.CreatePurchaseOrder(req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+func (h *PurchaseOrderHandler) List(c *gin.Context) {
+	var filter repository.PurchaseOrderFilter
+	var err error
+
+	if filter.SupplierID, err = parseUintQuery(c, "supplier_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status := c.Query("status"); status != "" {
+		value := models.PurchaseOrderStatus(status)
+		filter.Status = &value
+	}
+
+	orders, err := h.service.ListPurchaseOrders(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *PurchaseOrderHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	order, err := h.service.GetPurchaseOrderByID(uint(id))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *PurchaseOrderHandler) ChangeStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.PurchaseOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	order, err := h.service.ChangeStatus(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *PurchaseOrderHandler) Receive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.GoodsReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	receipt, err := h.service.ReceiveGoods(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, receipt)
+}
+
+func (h *PurchaseOrderHandler) ListReceipts(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	receipts, err := h.service.ListReceipts(uint(id))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipts)
+}
+
+func (h *PurchaseOrderHandler) MarginReport(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.Parse(reportDateLayout, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "параметр from должен быть в формате YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.Parse(reportDateLayout, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "параметр to должен быть в формате YYYY-MM-DD"})
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	rows, err := h.service.MarginReport(from, to)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
+
+func (h *PurchaseOrderHandler) writeServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPurchaseOrderNotFound),
+		errors.Is(err, services.ErrSupplierNotFound),
+		errors.Is(err, services.ErrBranchNotFound),
+		errors.Is(err, services.ErrMedicineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidStatusChange),
+		errors.Is(err, repository.ErrPurchaseOrderNotReceivable),
+		errors.Is(err, repository.ErrReceiptExceedsOrdered),
+		errors.Is(err, repository.ErrLotRequired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
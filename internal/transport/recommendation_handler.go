@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type RecommendationHandler struct {
+	service services.RecommendationService
+}
+
+func NewRecommendationHandler(service services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{service: service}
+}
+
+func (h *RecommendationHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/medicines/:id/recommendations", h.List)
+}
+
+func (h *RecommendationHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	limit, err := parseIntQuery(c, "limit", services.DefaultRecommendationLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recommendations, err := h.service.GetRecommendations(uint(id), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
@@ -1,11 +1,13 @@
 package transport
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/repository"
 	"github.com/kuduzow/team-4-pharmacy/internal/services"
 )
 
@@ -16,6 +18,11 @@ type ReviewHandler struct {
 func (h *ReviewHandler) RegisterRoutes(r *gin.Engine) {
 	reviews := r.Group("/reviews")
 	{
+		reviews.GET("/moderation", h.ListForModeration)
+		reviews.POST("/:id/approve", h.ApproveReview)
+		reviews.POST("/:id/reject", h.RejectReview)
+		reviews.POST("/:id/votes", h.VoteReview)
+		reviews.DELETE("/:id/votes/:user_id", h.RemoveVote)
 		reviews.GET("/:id", h.GetByID)
 		reviews.PATCH("/:id", h.UpdateReview)
 		reviews.DELETE("/:id", h.DeleteReview)
@@ -54,6 +61,14 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
 
 	review, err := h.service.CreateReview(req)
 	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) || errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrReviewAlreadyExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -136,8 +151,26 @@ func (h *ReviewHandler) ListByMedicineID(c *gin.Context) {
 		return
 	}
 
-	reviews, err := h.service.ListByMedicineID(uint(medicineID))
+	filter := repository.ReviewListFilter{Sort: repository.ReviewSort(c.Query("sort"))}
+
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if token := c.Query("page_token"); token != "" {
+		if filter.Offset, err = services.DecodePageToken(token); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	reviews, err := h.service.ListByMedicineID(uint(medicineID), filter)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidReviewSort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -156,8 +189,128 @@ func (h *ReviewHandler) GetAvgRating(c *gin.Context) {
 
 	avg, err := h.service.GetAvgRating(uint(medicineID))
 	if err != nil {
+		if errors.Is(err, services.ErrMedicineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, avg)
 }
+
+func (h *ReviewHandler) ListForModeration(c *gin.Context) {
+	var filter repository.ReviewListFilter
+	var err error
+
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if token := c.Query("page_token"); token != "" {
+		if filter.Offset, err = services.DecodePageToken(token); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	reviews, err := h.service.ListForModeration(c.Query("status"), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReviewStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+func (h *ReviewHandler) ApproveReview(c *gin.Context) {
+	h.moderateReview(c, h.service.ApproveReview)
+}
+
+func (h *ReviewHandler) RejectReview(c *gin.Context) {
+	h.moderateReview(c, h.service.RejectReview)
+}
+
+func (h *ReviewHandler) moderateReview(c *gin.Context, moderate func(uint, models.ReviewModerationRequest) (*models.Review, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req models.ReviewModerationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	review, err := moderate(uint(id), req)
+	if err != nil {
+		if errors.Is(err, services.ReviewNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *ReviewHandler) VoteReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req models.ReviewVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.service.VoteReview(uint(id), req)
+	if err != nil {
+		if errors.Is(err, services.ReviewNotFound) || errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *ReviewHandler) RemoveVote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	if err := h.service.RemoveVote(uint(id), uint(userID)); err != nil {
+		if errors.Is(err, services.ReviewNotFound) || errors.Is(err, services.ErrReviewVoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
@@ -15,6 +15,20 @@ func RegisterRoutes(
 	reviewService services.ModelService,
 	userService services.UserService,
 	cartService services.CartService,
+	activeIngredientService services.ActiveIngredientService,
+	batchService services.BatchService,
+	inventoryService services.InventoryService,
+	branchService services.BranchService,
+	supplierService services.SupplierService,
+	purchaseOrderService services.PurchaseOrderService,
+	catalogService services.CatalogService,
+	medicineImageService services.MedicineImageService,
+	priceService services.PriceService,
+	productService services.ProductService,
+	cartItemService services.CartItemService,
+	manufacturerService services.ManufacturerService,
+	subscriptionService services.SubscriptionService,
+	recommendationService services.RecommendationService,
 ) {
 	categoryHandler := NewCategoryHandler(categoryService)
 	medicineHandler := NewMedicineHandler(medicineService)
@@ -24,6 +38,20 @@ func RegisterRoutes(
 	reviewHandler := NewReviewHandler(reviewService)
 	userHandler := NewUserHandler(userService)
 	cartHandler := NewCartHandler(cartService)
+	activeIngredientHandler := NewActiveIngredientHandler(activeIngredientService)
+	batchHandler := NewBatchHandler(batchService)
+	inventoryHandler := NewInventoryHandler(inventoryService)
+	branchHandler := NewBranchHandler(branchService)
+	supplierHandler := NewSupplierHandler(supplierService)
+	purchaseOrderHandler := NewPurchaseOrderHandler(purchaseOrderService)
+	catalogHandler := NewCatalogHandler(catalogService)
+	medicineImageHandler := NewMedicineImageHandler(medicineImageService)
+	priceHandler := NewPriceHandler(priceService)
+	productHandler := NewProductHandler(productService)
+	cartItemHandler := NewCartItemHandler(cartItemService)
+	manufacturerHandler := NewManufacturerHandler(manufacturerService)
+	subscriptionHandler := NewSubscriptionHandler(subscriptionService)
+	recommendationHandler := NewRecommendationHandler(recommendationService)
 
 	categoryHandler.RegisterRoutes(router)
 	medicineHandler.RegisterRoutes(router)
@@ -33,5 +61,19 @@ func RegisterRoutes(
 	reviewHandler.RegisterRoutes(router)
 	userHandler.RegisterRoutes(router)
 	cartHandler.RegisterRoutes(router)
+	activeIngredientHandler.RegisterRoutes(router)
+	batchHandler.RegisterRoutes(router)
+	inventoryHandler.RegisterRoutes(router)
+	branchHandler.RegisterRoutes(router)
+	supplierHandler.RegisterRoutes(router)
+	purchaseOrderHandler.RegisterRoutes(router)
+	catalogHandler.RegisterRoutes(router)
+	medicineImageHandler.RegisterRoutes(router)
+	priceHandler.RegisterRoutes(router)
+	productHandler.RegisterRoutes(router)
+	cartItemHandler.RegisterRoutes(router)
+	manufacturerHandler.RegisterRoutes(router)
+	subscriptionHandler.RegisterRoutes(router)
+	recommendationHandler.RegisterRoutes(router)
 
 }
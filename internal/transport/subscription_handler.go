@@ -0,0 +1,101 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type SubscriptionHandler struct {
+	service services.SubscriptionService
+}
+
+func NewSubscriptionHandler(service services.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{service: service}
+}
+
+func (h *SubscriptionHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/medicines/:id/subscriptions", h.Subscribe)
+
+	users := r.Group("/users/:id/subscriptions")
+	{
+		users.GET("", h.ListByUser)
+		users.DELETE("/:subscription_id", h.Unsubscribe)
+	}
+}
+
+func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.SubscriptionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	subscription, err := h.service.Subscribe(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *SubscriptionHandler) ListByUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	subscriptions, err := h.service.ListUserSubscriptions(uint(userID))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *SubscriptionHandler) Unsubscribe(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	subscriptionID, err := strconv.ParseUint(c.Param("subscription_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный subscription_id"})
+		return
+	}
+
+	if err := h.service.Unsubscribe(uint(userID), uint(subscriptionID)); err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *SubscriptionHandler) writeServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSubscriptionNotFound),
+		errors.Is(err, services.ErrMedicineNotFound),
+		errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyInStock):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,121 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kuduzow/team-4-pharmacy/internal/models"
+	"github.com/kuduzow/team-4-pharmacy/internal/services"
+)
+
+type SupplierHandler struct {
+	service services.SupplierService
+}
+
+func NewSupplierHandler(service services.SupplierService) *SupplierHandler {
+	return &SupplierHandler{service: service}
+}
+
+func (h *SupplierHandler) RegisterRoutes(r *gin.Engine) {
+	suppliers := r.Group("/suppliers")
+	{
+		suppliers.POST("", h.Create)
+		suppliers.GET("", h.GetAll)
+		suppliers.GET("/:id", h.Get)
+		suppliers.PATCH("/:id", h.Update)
+		suppliers.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *SupplierHandler) Create(c *gin.Context) {
+	var req models.SupplierCreateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	supplier, err := h.service.CreateSupplier(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, supplier)
+}
+
+func (h *SupplierHandler) GetAll(c *gin.Context) {
+	suppliers, err := h.service.GetAllSuppliers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, suppliers)
+}
+
+func (h *SupplierHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	supplier, err := h.service.GetSupplierByID(uint(id))
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, supplier)
+}
+
+func (h *SupplierHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	var req models.SupplierUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный JSON"})
+		return
+	}
+
+	supplier, err := h.service.UpdateSupplier(uint(id), req)
+	if err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, supplier)
+}
+
+func (h *SupplierHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный id"})
+		return
+	}
+
+	if err := h.service.DeleteSupplier(uint(id)); err != nil {
+		h.writeServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *SupplierHandler) writeServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSupplierNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSupplierInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}